@@ -0,0 +1,263 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package apigw verifies the JWT which BlueKing APIGW attaches to the requests
+// forwarded to a plugin, and extracts the caller identity from it.
+//
+// Only the Go standard library is used, so the plugin framework does not
+// depend on the APIGW SDK.
+package apigw
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+// These headers are set by BlueKing APIGW on the forwarded requests.
+const (
+	HeaderJWT       = "X-Bkapi-JWT"
+	HeaderRequestID = "X-Bkapi-Request-Id"
+	HeaderTenantID  = "X-Bk-Tenant-Id"
+	HeaderLanguage  = "Blueking-Language"
+)
+
+// DefaultIssuer is the issuer of the JWT signed by BlueKing APIGW.
+const DefaultIssuer = "APIGW"
+
+// These errors are returned by Verifier, wrapped with detail message.
+var (
+	ErrMissingToken    = errors.New("apigw jwt is missing")
+	ErrInvalidToken    = errors.New("apigw jwt is invalid")
+	ErrUnknownGateway  = errors.New("apigw jwt is signed by unknown gateway")
+	ErrTokenExpired    = errors.New("apigw jwt is expired")
+	ErrAppNotVerified  = errors.New("apigw jwt app is not verified")
+	ErrUserNotVerified = errors.New("apigw jwt user is not verified")
+)
+
+// KeySet stores the RSA public keys of gateways by gateway name, which is
+// the kid of the JWT header.
+type KeySet map[string]*rsa.PublicKey
+
+// AddPEM parses a PEM encoded public key and stores it for gateway.
+func (s KeySet) AddPEM(gateway string, data []byte) error {
+	key, err := ParsePublicKey(data)
+	if err != nil {
+		return err
+	}
+	s[gateway] = key
+	return nil
+}
+
+// ParsePublicKey parses a PEM encoded PKIX or PKCS1 RSA public key.
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem block found in public key")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not a rsa public key")
+	}
+	return rsaKey, nil
+}
+
+// Config stores the options of a Verifier.
+type Config struct {
+	// Keys stores the public keys of trusted gateways.
+	Keys KeySet
+	// Issuer is the expected iss claim, DefaultIssuer is used when empty.
+	Issuer string
+	// RequireAppVerified rejects tokens whose app is not verified by APIGW.
+	RequireAppVerified bool
+	// RequireUserVerified rejects tokens whose user is not verified by APIGW.
+	RequireUserVerified bool
+	// Leeway is the clock skew tolerated when checking exp and nbf.
+	Leeway time.Duration
+}
+
+// A Verifier verifies BlueKing APIGW JWT.
+type Verifier struct {
+	config Config
+	now    func() time.Time
+}
+
+// NewVerifier returns a new Verifier instance.
+func NewVerifier(config Config) *Verifier {
+	if config.Issuer == "" {
+		config.Issuer = DefaultIssuer
+	}
+	return &Verifier{config: config, now: time.Now}
+}
+
+// AppClaims is the app part of APIGW JWT claims.
+type AppClaims struct {
+	AppCode   string `json:"app_code"`
+	BkAppCode string `json:"bk_app_code"`
+	Verified  bool   `json:"verified"`
+}
+
+// UserClaims is the user part of APIGW JWT claims.
+type UserClaims struct {
+	Username   string `json:"username"`
+	BkUsername string `json:"bk_username"`
+	Verified   bool   `json:"verified"`
+}
+
+// Claims is the payload of APIGW JWT.
+type Claims struct {
+	Issuer    string     `json:"iss"`
+	ExpiresAt int64      `json:"exp"`
+	NotBefore int64      `json:"nbf"`
+	App       AppClaims  `json:"app"`
+	User      UserClaims `json:"user"`
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// An Identity store the caller identity of a request verified by APIGW.
+type Identity struct {
+	GatewayName  string
+	AppCode      string
+	AppVerified  bool
+	Username     string
+	UserVerified bool
+	TenantID     string
+	RequestID    string
+	Language     string
+}
+
+// Caller converts the identity to the caller of plugin context.
+func (i Identity) Caller() kit.Caller {
+	return kit.Caller{
+		AppCode:   i.AppCode,
+		Operator:  i.Username,
+		TenantID:  i.TenantID,
+		RequestID: i.RequestID,
+		Language:  i.Language,
+	}
+}
+
+// Verify checks the signature, issuer and time claims of token, and returns
+// the gateway name and claims of it.
+func (v *Verifier) Verify(token string) (string, Claims, error) {
+	var claims Claims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", claims, fmt.Errorf("%w: token should have 3 parts", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return "", claims, fmt.Errorf("%w: decode header: %v", ErrInvalidToken, err)
+	}
+	if h.Alg != "RS256" {
+		return "", claims, fmt.Errorf("%w: unsupported alg %s", ErrInvalidToken, h.Alg)
+	}
+	key, found := v.config.Keys[h.Kid]
+	if !found {
+		return "", claims, fmt.Errorf("%w: %s", ErrUnknownGateway, h.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", claims, fmt.Errorf("%w: decode signature: %v", ErrInvalidToken, err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return "", claims, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", claims, fmt.Errorf("%w: decode claims: %v", ErrInvalidToken, err)
+	}
+	if claims.Issuer != v.config.Issuer {
+		return "", claims, fmt.Errorf("%w: unexpected issuer %s", ErrInvalidToken, claims.Issuer)
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(v.config.Leeway)) {
+		return "", claims, ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(v.config.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return "", claims, fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	if v.config.RequireAppVerified && !claims.App.Verified {
+		return "", claims, ErrAppNotVerified
+	}
+	if v.config.RequireUserVerified && !claims.User.Verified {
+		return "", claims, ErrUserNotVerified
+	}
+
+	return h.Kid, claims, nil
+}
+
+// VerifyRequest verifies the APIGW JWT of r and returns the caller identity.
+func (v *Verifier) VerifyRequest(r *http.Request) (Identity, error) {
+	token := r.Header.Get(HeaderJWT)
+	if token == "" {
+		return Identity{}, ErrMissingToken
+	}
+
+	gateway, claims, err := v.Verify(token)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{
+		GatewayName:  gateway,
+		AppCode:      firstNonEmpty(claims.App.BkAppCode, claims.App.AppCode),
+		AppVerified:  claims.App.Verified,
+		Username:     firstNonEmpty(claims.User.BkUsername, claims.User.Username),
+		UserVerified: claims.User.Verified,
+		TenantID:     r.Header.Get(HeaderTenantID),
+		RequestID:    r.Header.Get(HeaderRequestID),
+		Language:     r.Header.Get(HeaderLanguage),
+	}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
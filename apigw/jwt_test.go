@@ -0,0 +1,172 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package apigw
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+var testNow = time.Unix(1700000000, 0)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, h map[string]interface{}, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(h) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":  "APIGW",
+		"exp":  testNow.Add(time.Minute).Unix(),
+		"nbf":  testNow.Add(-time.Minute).Unix(),
+		"app":  map[string]interface{}{"bk_app_code": "bk_sops", "verified": true},
+		"user": map[string]interface{}{"bk_username": "admin", "verified": true},
+	}
+}
+
+func newTestVerifier(key *rsa.PrivateKey, config Config) *Verifier {
+	config.Keys = KeySet{"bk-plugin": &key.PublicKey}
+	v := NewVerifier(config)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key := newTestKey(t)
+
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	parsed, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	assert.NoError(t, err)
+	assert.Equal(t, &key.PublicKey, parsed)
+
+	pkcs1 := x509.MarshalPKCS1PublicKey(&key.PublicKey)
+	keys := KeySet{}
+	assert.NoError(t, keys.AddPEM("bk-plugin", pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pkcs1})))
+	assert.Equal(t, &key.PublicKey, keys["bk-plugin"])
+
+	_, err = ParsePublicKey([]byte("not a pem"))
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+	v := newTestVerifier(key, Config{RequireAppVerified: true, RequireUserVerified: true})
+	h := map[string]interface{}{"alg": "RS256", "kid": "bk-plugin"}
+
+	gateway, claims, err := v.Verify(signTestToken(t, key, h, testClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, "bk-plugin", gateway)
+	assert.Equal(t, "bk_sops", claims.App.BkAppCode)
+	assert.Equal(t, "admin", claims.User.BkUsername)
+
+	withClaim := func(key string, value interface{}) map[string]interface{} {
+		claims := testClaims()
+		claims[key] = value
+		return claims
+	}
+
+	var cases = []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{"malformed", "a.b", ErrInvalidToken},
+		{"bad signature", signTestToken(t, otherKey, h, testClaims()), ErrInvalidToken},
+		{"unknown gateway", signTestToken(t, key, map[string]interface{}{"alg": "RS256", "kid": "other"}, testClaims()), ErrUnknownGateway},
+		{"unsupported alg", signTestToken(t, key, map[string]interface{}{"alg": "HS256", "kid": "bk-plugin"}, testClaims()), ErrInvalidToken},
+		{"issuer", signTestToken(t, key, h, withClaim("iss", "other")), ErrInvalidToken},
+		{"expired", signTestToken(t, key, h, withClaim("exp", testNow.Add(-time.Second).Unix())), ErrTokenExpired},
+		{"not before", signTestToken(t, key, h, withClaim("nbf", testNow.Add(time.Minute).Unix())), ErrInvalidToken},
+		{"app not verified", signTestToken(t, key, h, withClaim("app", map[string]interface{}{"bk_app_code": "bk_sops"})), ErrAppNotVerified},
+		{"user not verified", signTestToken(t, key, h, withClaim("user", map[string]interface{}{"bk_username": "admin"})), ErrUserNotVerified},
+	}
+
+	for _, c := range cases {
+		_, _, err := v.Verify(c.token)
+		assert.True(t, errors.Is(err, c.expected), "case %s: unexpected error %v", c.name, err)
+	}
+}
+
+func TestVerifyLeeway(t *testing.T) {
+	key := newTestKey(t)
+	v := newTestVerifier(key, Config{Leeway: time.Minute})
+	claims := testClaims()
+	claims["exp"] = testNow.Add(-30 * time.Second).Unix()
+
+	_, _, err := v.Verify(signTestToken(t, key, map[string]interface{}{"alg": "RS256", "kid": "bk-plugin"}, claims))
+
+	assert.NoError(t, err)
+}
+
+func TestVerifyRequest(t *testing.T) {
+	key := newTestKey(t)
+	v := newTestVerifier(key, Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/bk_plugin/invoke/1.0.0", nil)
+	_, err := v.VerifyRequest(req)
+	assert.Equal(t, ErrMissingToken, err)
+
+	req.Header.Set(HeaderJWT, signTestToken(t, key, map[string]interface{}{"alg": "RS256", "kid": "bk-plugin"}, testClaims()))
+	req.Header.Set(HeaderRequestID, "req-1")
+	req.Header.Set(HeaderTenantID, "default")
+	req.Header.Set(HeaderLanguage, "en")
+
+	identity, err := v.VerifyRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{
+		GatewayName:  "bk-plugin",
+		AppCode:      "bk_sops",
+		AppVerified:  true,
+		Username:     "admin",
+		UserVerified: true,
+		TenantID:     "default",
+		RequestID:    "req-1",
+		Language:     "en",
+	}, identity)
+	assert.Equal(t, kit.Caller{
+		AppCode:   "bk_sops",
+		Operator:  "admin",
+		TenantID:  "default",
+		RequestID: "req-1",
+		Language:  "en",
+	}, identity.Caller())
+}
@@ -0,0 +1,61 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package apigw
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+// CodeUnauthorized is the response code returned when APIGW JWT verification fails.
+const CodeUnauthorized = 40100
+
+type identityContextKey struct{}
+
+// WithIdentity returns a request carrying the verified caller identity.
+func WithIdentity(r *http.Request, identity Identity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity))
+}
+
+// IdentityFrom returns the caller identity attached by Middleware.
+func IdentityFrom(r *http.Request) (Identity, bool) {
+	identity, ok := r.Context().Value(identityContextKey{}).(Identity)
+	return identity, ok
+}
+
+// Middleware verifies the APIGW JWT of each request before calling next, and
+// attaches the caller identity to the request, the identity is also attached
+// as pluginapi caller so it can be read by pluginapi.CallerFrom. Requests failed verification
+// get a 401 response in the standard envelope.
+//
+// It can be applied to plugin APIs by pluginapi.Router Use method.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := v.VerifyRequest(r)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(protocol.Error(CodeUnauthorized, err.Error()))
+			return
+		}
+		r = WithIdentity(r, identity)
+		next.ServeHTTP(w, pluginapi.WithCaller(r, identity.Caller()))
+	})
+}
+
+// Protect wraps a plugin API handler with Middleware, so it can be passed to
+// pluginapi.Router methods directly.
+func (v *Verifier) Protect(handler http.HandlerFunc) http.HandlerFunc {
+	return v.Middleware(handler).ServeHTTP
+}
@@ -0,0 +1,58 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package apigw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+)
+
+func TestMiddleware(t *testing.T) {
+	key := newTestKey(t)
+	v := newTestVerifier(key, Config{})
+
+	var got Identity
+	var gotCaller kit.Caller
+	handler := v.Protect(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := IdentityFrom(r)
+		assert.True(t, ok)
+		got = identity
+		gotCaller, ok = pluginapi.CallerFrom(r)
+		assert.True(t, ok)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// reject request without token
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"result":false,"code":40100,"message":"apigw jwt is missing","data":null}`, rec.Body.String())
+
+	// pass identity to handler
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set(HeaderJWT, signTestToken(t, key, map[string]interface{}{"alg": "RS256", "kid": "bk-plugin"}, testClaims()))
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "bk_sops", got.AppCode)
+	assert.Equal(t, "admin", got.Username)
+	assert.Equal(t, got.Caller(), gotCaller)
+}
+
+func TestIdentityFromWithoutMiddleware(t *testing.T) {
+	_, ok := IdentityFrom(httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	assert.False(t, ok)
+}
@@ -0,0 +1,154 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package cli implements the bkplugin command line tool.
+//
+// The commands operate on the plugin versions installed to hub, so the tool
+// runs inside the plugin binary: call Main after installing the versions in
+// main function, then run the binary with the bkplugin argument, e.g.
+//
+//	bk-plugin-go bkplugin compat 1.0.0 1.1.0
+//
+// The commands list versions, print the meta and detail payloads, export
+// schemas, invoke a version with an in-memory runtime, check compatibility
+// between versions and lint the installed versions, run the tool with help
+// argument for details.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Command is the argument which makes a plugin binary run the command line
+// tool instead of the runtime.
+const Command = "bkplugin"
+
+// Exit codes returned by Run.
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+// A command is a subcommand of the tool, standalone commands do not operate on
+// the installed versions, so they can run outside the plugin binary.
+type command struct {
+	name       string
+	usage      string
+	summary    string
+	standalone bool
+	run        func(env *env, args []string) int
+}
+
+var commands = map[string]*command{}
+
+func register(c *command) {
+	commands[c.name] = c
+}
+
+// Standalone reports whether the command name does not operate on the
+// installed versions and can run outside the plugin binary.
+func Standalone(name string) bool {
+	c, found := commands[name]
+	return found && c.standalone
+}
+
+// env stores the outputs of a command run.
+type env struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (e *env) errorf(format string, args ...interface{}) int {
+	fmt.Fprintf(e.stderr, Command+": "+format+"\n", args...)
+	return ExitFailure
+}
+
+func (e *env) flagSet(c *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: %s %s\n\n%s\n", Command, c.usage, c.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseInterspersed parses args with fs allowing flags after positional
+// arguments, and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// parseStatus returns the exit code for a flag parsing error.
+func parseStatus(err error) int {
+	if err == flag.ErrHelp {
+		return ExitOK
+	}
+	return ExitUsage
+}
+
+func (e *env) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(e.stderr, "usage: %s <command> [arguments]\n\ncommands:\n", Command)
+	for _, name := range names {
+		fmt.Fprintf(e.stderr, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// Run runs the command line tool with args, which do not include the program
+// name, and returns the exit code.
+func Run(args []string, stdout io.Writer, stderr io.Writer) int {
+	e := &env{stdout: stdout, stderr: stderr}
+	if len(args) == 0 {
+		e.usage()
+		return ExitUsage
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		e.usage()
+		return ExitOK
+	}
+
+	c, found := commands[args[0]]
+	if !found {
+		fmt.Fprintf(stderr, "%s: unknown command %q\n", Command, args[0])
+		e.usage()
+		return ExitUsage
+	}
+	return c.run(e, args[1:])
+}
+
+// Main runs the command line tool and exits if the first argument of the
+// process is Command, otherwise it returns and the caller goes on starting
+// the runtime.
+func Main() {
+	if len(os.Args) < 2 || os.Args[1] != Command {
+		return
+	}
+	os.Exit(Run(os.Args[2:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,133 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+type testPlugin struct {
+	version string
+}
+
+func (p *testPlugin) Version() string {
+	return p.version
+}
+
+func (p *testPlugin) Desc() string {
+	return "test plugin " + p.version
+}
+
+func (p *testPlugin) Execute(c *kit.Context) error {
+	return nil
+}
+
+type testInputs struct {
+	Name string `json:"name"`
+}
+
+type testOutputs struct {
+	Result string `json:"result"`
+}
+
+type testNewInputs struct {
+	Name  string `json:"name"`
+	Count int    `json:"count,omitempty"`
+}
+
+type testNewOutputs struct {
+	Message string `json:"message"`
+}
+
+type pollInputs struct {
+	Polls int `json:"polls"`
+}
+
+type callbackData struct {
+	Result string `json:"result"`
+}
+
+// pollPlugin polls for inputs polls times, then waits for callback and writes
+// the callback result to outputs.
+type pollPlugin struct{}
+
+func (p *pollPlugin) Version() string {
+	return "0.1.0"
+}
+
+func (p *pollPlugin) Desc() string {
+	return ""
+}
+
+func (p *pollPlugin) Execute(c *kit.Context) error {
+	var inputs pollInputs
+	if err := c.ReadInputs(&inputs); err != nil {
+		return err
+	}
+	if c.State() == constants.StateCallback {
+		var data callbackData
+		if err := c.ReadCallback(&data); err != nil {
+			return err
+		}
+		return c.WriteOutputs(testOutputs{Result: data.Result})
+	}
+	if inputs.Polls < 0 {
+		return errors.New("polls is negative")
+	}
+	if c.InvokeCount() <= inputs.Polls {
+		c.WaitPoll(time.Millisecond)
+		return nil
+	}
+	c.WaitCallback(time.Minute)
+	return nil
+}
+
+var installOnce sync.Once
+
+// installTestVersions installs 1.0.0, 1.1.0 with compatible changes, 2.0.0
+// with breaking changes and 0.1.0 of pollPlugin.
+func installTestVersions() {
+	installOnce.Do(func() {
+		hub.MustInstallV2(&pollPlugin{}, hub.PluginSpec{Inputs: pollInputs{}, Outputs: testOutputs{}, Form: []byte(`{"polls": {"ui:remote": {"path": "/polls"}}}`)})
+		hub.MustInstallV2(&testPlugin{version: "1.0.0"}, hub.PluginSpec{Inputs: testInputs{}, Outputs: testOutputs{}})
+		hub.MustInstallV2(&testPlugin{version: "1.1.0"}, hub.PluginSpec{Inputs: testNewInputs{}, Outputs: testOutputs{}})
+		hub.MustInstallV2(&testPlugin{version: "2.0.0"}, hub.PluginSpec{Inputs: testNewInputs{}, Outputs: testNewOutputs{}})
+	})
+}
+
+func run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunUsage(t *testing.T) {
+	code, _, stderr := run()
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "usage: bkplugin <command> [arguments]")
+	assert.Contains(t, stderr, "compat")
+
+	code, _, _ = run("help")
+	assert.Equal(t, ExitOK, code)
+
+	code, _, stderr = run("unknown")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, `bkplugin: unknown command "unknown"`)
+}
@@ -0,0 +1,71 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+)
+
+func init() {
+	register(&command{
+		name:  "compat",
+		usage: "compat [-json] [old-version new-version]",
+		summary: "Compare the schemas of two installed versions, the latest two by default,\n" +
+			"and exit with status 1 if there are breaking changes.",
+		run: runCompat,
+	})
+}
+
+func runCompat(e *env, args []string) int {
+	fs := e.flagSet(commands["compat"])
+	asJSON := fs.Bool("json", false, "print the report in JSON format")
+	if err := fs.Parse(args); err != nil {
+		return parseStatus(err)
+	}
+
+	var oldVersion, newVersion string
+	switch fs.NArg() {
+	case 0:
+		versions := hub.GetPluginVersions()
+		if len(versions) < 2 {
+			return e.errorf("compat: at least 2 installed versions are required, got %d", len(versions))
+		}
+		oldVersion, newVersion = versions[1], versions[0]
+	case 2:
+		oldVersion, newVersion = fs.Arg(0), fs.Arg(1)
+	default:
+		fs.Usage()
+		return ExitUsage
+	}
+
+	report, err := hub.CompareVersions(oldVersion, newVersion)
+	if err != nil {
+		return e.errorf("compat: %v", err)
+	}
+
+	if *asJSON {
+		if err := e.printJSON(report); err != nil {
+			return e.errorf("compat: %v", err)
+		}
+	} else {
+		fmt.Fprintf(e.stdout, "%s -> %s\n", oldVersion, newVersion)
+		for _, c := range report.Changes {
+			fmt.Fprintf(e.stdout, "  %s\n", c)
+		}
+		fmt.Fprintf(e.stdout, "%d changes, %d breaking\n", len(report.Changes), len(report.Breaking()))
+	}
+
+	if !report.Compatible() {
+		return ExitFailure
+	}
+	return ExitOK
+}
@@ -0,0 +1,50 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+)
+
+func TestCompat(t *testing.T) {
+	installTestVersions()
+
+	code, stdout, _ := run("compat", "1.0.0", "1.1.0")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "1.0.0 -> 1.1.0\n  compatible inputs.count: property added\n1 changes, 0 breaking\n", stdout)
+
+	code, stdout, _ = run("compat")
+	assert.Equal(t, ExitFailure, code)
+	assert.Equal(t, "1.1.0 -> 2.0.0\n"+
+		"  compatible outputs.message: property added\n"+
+		"  breaking outputs.result: property removed\n"+
+		"2 changes, 1 breaking\n", stdout)
+
+	code, stdout, _ = run("compat", "-json", "1.1.0", "2.0.0")
+	assert.Equal(t, ExitFailure, code)
+	var report hub.CompatReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.Equal(t, "2.0.0", report.NewVersion)
+	assert.Len(t, report.Breaking(), 1)
+
+	code, _, stderr := run("compat", "1.0.0", "3.0.0")
+	assert.Equal(t, ExitFailure, code)
+	assert.Equal(t, "bkplugin: compat: can not found plugin for version: 3.0.0\n", stderr)
+
+	code, _, stderr = run("compat", "1.0.0")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "usage: bkplugin compat [-json] [old-version new-version]")
+}
@@ -0,0 +1,235 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/openapi"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+func init() {
+	register(&command{
+		name:    "versions",
+		usage:   "versions [-json]",
+		summary: "List the installed versions in new to old order.",
+		run:     runVersions,
+	})
+	register(&command{
+		name:    "meta",
+		usage:   "meta [-code code] [-description description]",
+		summary: "Print the meta payload of the plugin in JSON format.",
+		run:     runMeta,
+	})
+	register(&command{
+		name:    "detail",
+		usage:   "detail [-callback] [-tenant id] <version>",
+		summary: "Print the detail payload of a version in JSON format.",
+		run:     runDetail,
+	})
+	register(&command{
+		name:  "schema",
+		usage: "schema [-kind inputs|context_inputs|outputs] [-openapi] [-o dir] [version]",
+		summary: "Print the schemas of a version, or all versions if version is omitted,\n" +
+			"or write them to <dir>/<version>/<kind>.json with -o.",
+		run: runSchema,
+	})
+}
+
+// printJSON writes v to stdout in indented JSON format.
+func (e *env) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(e.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func runVersions(e *env, args []string) int {
+	fs := e.flagSet(commands["versions"])
+	asJSON := fs.Bool("json", false, "print the versions in JSON format")
+	if err := fs.Parse(args); err != nil {
+		return parseStatus(err)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	versions := hub.GetPluginVersions()
+	if *asJSON {
+		if err := e.printJSON(versions); err != nil {
+			return e.errorf("versions: %v", err)
+		}
+		return ExitOK
+	}
+	for _, version := range versions {
+		fmt.Fprintln(e.stdout, version)
+	}
+	return ExitOK
+}
+
+func runMeta(e *env, args []string) int {
+	fs := e.flagSet(commands["meta"])
+	code := fs.String("code", "", "code of the plugin")
+	description := fs.String("description", "", "description of the plugin")
+	if err := fs.Parse(args); err != nil {
+		return parseStatus(err)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	meta := protocol.BuildMeta(protocol.MetaOptions{
+		Code:        *code,
+		Description: *description,
+		AllowScope:  hub.GetOptions().AllowScope,
+	})
+	if err := e.printJSON(meta); err != nil {
+		return e.errorf("meta: %v", err)
+	}
+	return ExitOK
+}
+
+func runDetail(e *env, args []string) int {
+	fs := e.flagSet(commands["detail"])
+	callback := fs.Bool("callback", hub.GetOptions().EnablePluginCallback, "set enable_plugin_callback of the payload")
+	tenant := fs.String("tenant", "", "tenant `id` whose options override -callback")
+	if err := fs.Parse(args); err != nil {
+		return parseStatus(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	opts := pluginapi.DetailOptions(fs.Arg(0), protocol.DetailOptions{TenantID: *tenant, EnablePluginCallback: *callback})
+	detail, err := protocol.BuildDetail(fs.Arg(0), opts)
+	if err != nil {
+		return e.errorf("detail: %v", err)
+	}
+	if err := e.printJSON(detail); err != nil {
+		return e.errorf("detail: %v", err)
+	}
+	return ExitOK
+}
+
+var schemaKinds = []hub.SchemaKind{hub.SchemaInputs, hub.SchemaContextInputs, hub.SchemaOutputs}
+
+// versionSchemas returns the schemas of version by kind.
+func versionSchemas(version string) (map[hub.SchemaKind]map[string]interface{}, error) {
+	detail, err := hub.GetPluginDetail(version)
+	if err != nil {
+		return nil, err
+	}
+	return map[hub.SchemaKind]map[string]interface{}{
+		hub.SchemaInputs:        detail.InputsSchemaJSON(),
+		hub.SchemaContextInputs: detail.ContextInputsSchemaJSON(),
+		hub.SchemaOutputs:       detail.OutputsSchemaJSON(),
+	}, nil
+}
+
+func runSchema(e *env, args []string) int {
+	fs := e.flagSet(commands["schema"])
+	kind := fs.String("kind", "", "export only the schema of this kind")
+	asOpenAPI := fs.Bool("openapi", false, "export the OpenAPI document of the plugin instead")
+	dir := fs.String("o", "", "write the schemas to files in this directory")
+	if err := fs.Parse(args); err != nil {
+		return parseStatus(err)
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	switch hub.SchemaKind(*kind) {
+	case "", hub.SchemaInputs, hub.SchemaContextInputs, hub.SchemaOutputs:
+	default:
+		return e.errorf("schema: unknown schema kind %q", *kind)
+	}
+
+	if *asOpenAPI {
+		document, err := openapi.Generate(openapi.Options{})
+		if err != nil {
+			return e.errorf("schema: %v", err)
+		}
+		if *dir != "" {
+			if err := writeJSONFile(filepath.Join(*dir, "openapi.json"), document); err != nil {
+				return e.errorf("schema: %v", err)
+			}
+			return ExitOK
+		}
+		if err := e.printJSON(document); err != nil {
+			return e.errorf("schema: %v", err)
+		}
+		return ExitOK
+	}
+
+	versions := hub.GetPluginVersions()
+	if fs.NArg() == 1 {
+		versions = []string{fs.Arg(0)}
+	}
+
+	exported := map[string]map[hub.SchemaKind]map[string]interface{}{}
+	for _, version := range versions {
+		schemas, err := versionSchemas(version)
+		if err != nil {
+			return e.errorf("schema: %v", err)
+		}
+		if *kind != "" {
+			schemas = map[hub.SchemaKind]map[string]interface{}{hub.SchemaKind(*kind): schemas[hub.SchemaKind(*kind)]}
+		}
+		exported[version] = schemas
+	}
+
+	if *dir != "" {
+		for _, version := range versions {
+			for _, k := range schemaKinds {
+				s, found := exported[version][k]
+				if !found {
+					continue
+				}
+				path := filepath.Join(*dir, version, string(k)+".json")
+				if err := writeJSONFile(path, s); err != nil {
+					return e.errorf("schema: %v", err)
+				}
+				fmt.Fprintln(e.stdout, path)
+			}
+		}
+		return ExitOK
+	}
+
+	var out interface{} = exported
+	if fs.NArg() == 1 {
+		out = exported[fs.Arg(0)]
+		if *kind != "" {
+			out = exported[fs.Arg(0)][hub.SchemaKind(*kind)]
+		}
+	}
+	if err := e.printJSON(out); err != nil {
+		return e.errorf("schema: %v", err)
+	}
+	return ExitOK
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,100 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+)
+
+func TestVersions(t *testing.T) {
+	installTestVersions()
+
+	code, stdout, _ := run("versions")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "2.0.0\n1.1.0\n1.0.0\n0.1.0\n", stdout)
+
+	code, stdout, _ = run("versions", "-json")
+	assert.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `["2.0.0", "1.1.0", "1.0.0", "0.1.0"]`, stdout)
+}
+
+func TestMetaAndDetail(t *testing.T) {
+	installTestVersions()
+
+	code, stdout, _ := run("meta", "-code", "demo")
+	assert.Equal(t, ExitOK, code)
+	var meta map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &meta))
+	assert.Equal(t, "demo", meta["code"])
+	assert.Equal(t, []interface{}{"2.0.0", "1.1.0", "1.0.0", "0.1.0"}, meta["versions"])
+
+	code, stdout, _ = run("detail", "-callback", "1.0.0")
+	assert.Equal(t, ExitOK, code)
+	var detail map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &detail))
+	assert.Equal(t, "1.0.0", detail["version"])
+	assert.Equal(t, "test plugin 1.0.0", detail["desc"])
+	assert.Equal(t, true, detail["enable_plugin_callback"])
+
+	enabled := true
+	hub.Configure(hub.Options{Tenants: map[string]hub.TenantOptions{"tenant-a": {EnablePluginCallback: &enabled}}})
+	defer hub.Configure(hub.Options{})
+	code, stdout, _ = run("detail", "-tenant", "tenant-a", "1.0.0")
+	assert.Equal(t, ExitOK, code)
+	require.NoError(t, json.Unmarshal([]byte(stdout), &detail))
+	assert.Equal(t, true, detail["enable_plugin_callback"])
+
+	code, _, stderr := run("detail", "9.9.9")
+	assert.Equal(t, ExitFailure, code)
+	assert.Equal(t, "bkplugin: detail: can not found plugin for version: 9.9.9\n", stderr)
+}
+
+func TestSchema(t *testing.T) {
+	installTestVersions()
+
+	code, stdout, _ := run("schema", "-kind", "outputs", "1.0.0")
+	assert.Equal(t, ExitOK, code)
+	var s map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &s))
+	assert.Equal(t, map[string]interface{}{"result": map[string]interface{}{"type": "string"}}, s["properties"])
+
+	code, stdout, _ = run("schema")
+	assert.Equal(t, ExitOK, code)
+	var all map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &all))
+	assert.Len(t, all, 4)
+	assert.Len(t, all["2.0.0"], 3)
+
+	dir := t.TempDir()
+	code, stdout, _ = run("schema", "-o", dir, "1.1.0")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, filepath.Join(dir, "1.1.0", "inputs.json")+"\n"+
+		filepath.Join(dir, "1.1.0", "context_inputs.json")+"\n"+
+		filepath.Join(dir, "1.1.0", "outputs.json")+"\n", stdout)
+	data, err := os.ReadFile(filepath.Join(dir, "1.1.0", "inputs.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"count"`)
+
+	code, stdout, _ = run("schema", "-openapi")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, `"openapi": "3.0.3"`)
+
+	code, _, stderr := run("schema", "-kind", "forms")
+	assert.Equal(t, ExitFailure, code)
+	assert.Equal(t, "bkplugin: schema: unknown schema kind \"forms\"\n", stderr)
+}
@@ -0,0 +1,300 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/executor"
+	pluginruntime "github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/statemachine"
+)
+
+func init() {
+	register(&command{
+		name: "invoke",
+		usage: "invoke [-inputs file] [-context file] [-callback file] [-trace id]\n" +
+			"       [-wait] [-max-schedules n] [-v] <version>",
+		summary: "Invoke a version with an in-memory runtime, schedule it until it succeeds or\n" +
+			"fails, and print the result in JSON format. Files contain JSON data, \"-\" reads\n" +
+			"stdin. The callback data is sent the first time the plugin waits for callback.",
+		run: runInvoke,
+	})
+}
+
+// invokeResult is the result printed by invoke command.
+type invokeResult struct {
+	TraceID     string          `json:"trace_id"`
+	State       string          `json:"state"`
+	InvokeCount int             `json:"invoke_count"`
+	Outputs     json.RawMessage `json:"outputs"`
+	Error       string          `json:"error,omitempty"`
+}
+
+func runInvoke(e *env, args []string) int {
+	fs := e.flagSet(commands["invoke"])
+	inputsFile := fs.String("inputs", "", "file of the inputs data, {} if empty")
+	contextFile := fs.String("context", "", "file of the context inputs data, {} if empty")
+	callbackFile := fs.String("callback", "", "file of the callback data")
+	traceID := fs.String("trace", "local", "trace id of the invocation")
+	wait := fs.Bool("wait", false, "sleep for the poll interval before each schedule")
+	maxSchedules := fs.Int("max-schedules", 100, "maximum number of schedules")
+	verbose := fs.Bool("v", false, "print the plugin logs")
+	if err := fs.Parse(args); err != nil {
+		return parseStatus(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	version := fs.Arg(0)
+
+	reader := &localReader{}
+	var err error
+	if reader.inputs, err = readJSONFile(*inputsFile); err != nil {
+		return e.errorf("invoke: read inputs: %v", err)
+	}
+	if reader.contextInputs, err = readJSONFile(*contextFile); err != nil {
+		return e.errorf("invoke: read context inputs: %v", err)
+	}
+	var callback json.RawMessage
+	if *callbackFile != "" {
+		if callback, err = readJSONFile(*callbackFile); err != nil {
+			return e.errorf("invoke: read callback: %v", err)
+		}
+	}
+
+	logger := log.New()
+	logger.SetOutput(e.stderr)
+	if !*verbose {
+		logger.SetOutput(io.Discard)
+	}
+	entry := logger.WithField("trace_id", *traceID)
+
+	rt := newMemoryRuntime()
+	state, err := executor.Execute(*traceID, version, reader, rt, entry)
+	invokeCount := 1
+	e.step(invokeCount, state, rt)
+
+	for schedules := 0; err == nil && statemachine.IsWaiting(state); schedules++ {
+		if schedules >= *maxSchedules {
+			err = fmt.Errorf("plugin is still in state %s after %d schedules", state, *maxSchedules)
+			break
+		}
+		if state == constants.StateCallback {
+			if callback == nil {
+				err = fmt.Errorf("plugin is waiting for callback but there is no callback data to send")
+				break
+			}
+			// the callback data is sent once
+			reader.callback, callback = callback, nil
+		} else if *wait {
+			time.Sleep(rt.pollInterval)
+		}
+
+		invokeCount++
+		rt.reset()
+		err = executor.ScheduleWithState(*traceID, version, invokeCount, state, reader, rt, entry)
+		state = rt.state()
+		e.step(invokeCount, state, rt)
+		reader.callback = nil
+	}
+
+	result := invokeResult{
+		TraceID:     *traceID,
+		State:       state.String(),
+		InvokeCount: invokeCount,
+		Outputs:     rt.outputs.data[*traceID],
+	}
+	if err == nil && rt.err != nil {
+		err = rt.err
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if printErr := e.printJSON(result); printErr != nil {
+		return e.errorf("invoke: %v", printErr)
+	}
+	if err != nil {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// step prints the state of the invocation after the invokeCount-th call of
+// Execute to stderr.
+func (e *env) step(invokeCount int, state constants.State, rt *memoryRuntime) {
+	switch state {
+	case constants.StatePoll:
+		fmt.Fprintf(e.stderr, "#%d poll after %v\n", invokeCount, rt.pollInterval)
+	case constants.StateCallback:
+		fmt.Fprintf(e.stderr, "#%d callback within %v\n", invokeCount, rt.callbackTimeout)
+	default:
+		fmt.Fprintf(e.stderr, "#%d %s\n", invokeCount, state)
+	}
+}
+
+// readJSONFile reads JSON data from path, "-" means stdin, empty path means
+// an empty object.
+func readJSONFile(path string) (json.RawMessage, error) {
+	if path == "" {
+		return json.RawMessage("{}"), nil
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%s is not valid JSON", path)
+	}
+	return data, nil
+}
+
+// localReader reads the invocation data from memory.
+type localReader struct {
+	inputs        json.RawMessage
+	contextInputs json.RawMessage
+	callback      json.RawMessage
+}
+
+func (r *localReader) ReadInputs(v interface{}) error {
+	return json.Unmarshal(r.inputs, v)
+}
+
+func (r *localReader) ReadContextInputs(v interface{}) error {
+	return json.Unmarshal(r.contextInputs, v)
+}
+
+func (r *localReader) ReadCallback(v interface{}) error {
+	if r.callback == nil {
+		return fmt.Errorf("callback payload is not available")
+	}
+	return json.Unmarshal(r.callback, v)
+}
+
+// memoryStore stores objects in JSON format in memory.
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string]json.RawMessage
+}
+
+func (s *memoryStore) Write(traceID string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[traceID] = data
+	return nil
+}
+
+func (s *memoryStore) Read(traceID string, v interface{}) error {
+	s.mu.Lock()
+	data, found := s.data[traceID]
+	s.mu.Unlock()
+	if !found {
+		return fmt.Errorf("no data stored for trace %s", traceID)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// memoryRuntime is an in-memory runtime which records the scheduling requests
+// of the last call of Execute.
+type memoryRuntime struct {
+	outputs *memoryStore
+	context *memoryStore
+
+	polling         bool
+	pollInterval    time.Duration
+	waitingCallback bool
+	callbackTimeout time.Duration
+	success         bool
+	err             error
+}
+
+func newMemoryRuntime() *memoryRuntime {
+	return &memoryRuntime{
+		outputs: &memoryStore{data: map[string]json.RawMessage{}},
+		context: &memoryStore{data: map[string]json.RawMessage{}},
+	}
+}
+
+// reset clears the scheduling requests before next call of Execute.
+func (r *memoryRuntime) reset() {
+	r.polling = false
+	r.waitingCallback = false
+	r.success = false
+	r.err = nil
+}
+
+// state returns the state after the last schedule.
+func (r *memoryRuntime) state() constants.State {
+	switch {
+	case r.err != nil:
+		return constants.StateFail
+	case r.waitingCallback:
+		return constants.StateCallback
+	case r.polling:
+		return constants.StatePoll
+	case r.success:
+		return constants.StateSuccess
+	}
+	return constants.StateFail
+}
+
+func (r *memoryRuntime) GetOutputsStore() pluginruntime.ObjectStore {
+	return r.outputs
+}
+
+func (r *memoryRuntime) GetContextStore() pluginruntime.ObjectStore {
+	return r.context
+}
+
+func (r *memoryRuntime) SetPoll(traceID string, version string, invokeCount int, after time.Duration) error {
+	r.polling = true
+	r.pollInterval = after
+	return nil
+}
+
+func (r *memoryRuntime) SetCallback(traceID string, version string, invokeCount int, timeout time.Duration) error {
+	r.waitingCallback = true
+	r.callbackTimeout = timeout
+	return nil
+}
+
+func (r *memoryRuntime) PrepareCallback(traceID string, version string, invokeCount int, timeout time.Duration) (pluginruntime.CallbackPreparation, error) {
+	return pluginruntime.CallbackPreparation{ID: traceID, URL: "local://callback/" + traceID}, nil
+}
+
+func (r *memoryRuntime) SetFail(traceID string, err error) error {
+	r.err = err
+	return nil
+}
+
+func (r *memoryRuntime) SetSuccess(traceID string) error {
+	r.success = true
+	return nil
+}
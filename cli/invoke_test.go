@@ -0,0 +1,68 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, name string, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	return path
+}
+
+func TestInvoke(t *testing.T) {
+	installTestVersions()
+	inputs := writeTestFile(t, "inputs.json", `{"polls": 2}`)
+	callback := writeTestFile(t, "callback.json", `{"result": "done"}`)
+
+	code, stdout, stderr := run("invoke", "-inputs", inputs, "-callback", callback, "0.1.0")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "#1 poll after 1ms\n#2 poll after 1ms\n#3 callback within 1m0s\n#4 success\n", stderr)
+	assert.JSONEq(t, `{"trace_id": "local", "state": "success", "invoke_count": 4, "outputs": {"result": "done"}}`, stdout)
+}
+
+func TestInvokeWithoutCallback(t *testing.T) {
+	installTestVersions()
+
+	code, stdout, stderr := run("invoke", "-trace", "t1", "0.1.0")
+	assert.Equal(t, ExitFailure, code)
+	assert.Equal(t, "#1 callback within 1m0s\n", stderr)
+	assert.JSONEq(t, `{
+		"trace_id": "t1", "state": "callback", "invoke_count": 1, "outputs": null,
+		"error": "plugin is waiting for callback but there is no callback data to send"
+	}`, stdout)
+}
+
+func TestInvokeFailures(t *testing.T) {
+	installTestVersions()
+
+	code, stdout, _ := run("invoke", "-inputs", writeTestFile(t, "inputs.json", `{"polls": -1}`), "0.1.0")
+	assert.Equal(t, ExitFailure, code)
+	assert.JSONEq(t, `{"trace_id": "local", "state": "fail", "invoke_count": 1, "outputs": null, "error": "polls is negative"}`, stdout)
+
+	code, stdout, _ = run("invoke", "-inputs", writeTestFile(t, "inputs.json", `{"polls": 5}`), "-max-schedules", "2", "0.1.0")
+	assert.Equal(t, ExitFailure, code)
+	assert.JSONEq(t, `{
+		"trace_id": "local", "state": "poll", "invoke_count": 3, "outputs": null,
+		"error": "plugin is still in state poll after 2 schedules"
+	}`, stdout)
+
+	code, _, stderr := run("invoke", "-inputs", writeTestFile(t, "inputs.json", `{`), "0.1.0")
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, stderr, "bkplugin: invoke: read inputs: ")
+	assert.Contains(t, stderr, "is not valid JSON")
+}
@@ -0,0 +1,128 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+func init() {
+	register(&command{
+		name:  "lint",
+		usage: "lint [-strict]",
+		summary: "Check the installed versions for common problems, and exit with status 1 if\n" +
+			"there are errors, or warnings with -strict.",
+		run: runLint,
+	})
+}
+
+// A lintIssue is a problem found by lint command.
+type lintIssue struct {
+	version string
+	err     bool
+	message string
+}
+
+func (i lintIssue) String() string {
+	level := "warning"
+	if i.err {
+		level = "error"
+	}
+	if i.version == "" {
+		return fmt.Sprintf("%s: %s", level, i.message)
+	}
+	return fmt.Sprintf("%s: version %s: %s", level, i.version, i.message)
+}
+
+func runLint(e *env, args []string) int {
+	fs := e.flagSet(commands["lint"])
+	strict := fs.Bool("strict", false, "treat warnings as errors")
+	if err := fs.Parse(args); err != nil {
+		return parseStatus(err)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	issues := lint()
+	failed := 0
+	for _, issue := range issues {
+		if issue.err || *strict {
+			failed++
+		}
+		fmt.Fprintln(e.stdout, issue)
+	}
+	fmt.Fprintf(e.stdout, "%d issues, %d errors\n", len(issues), failed)
+	if failed > 0 {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// lint returns the issues of installed versions.
+func lint() []lintIssue {
+	var issues []lintIssue
+	versions := hub.GetPluginVersions()
+	if len(versions) == 0 {
+		return []lintIssue{{err: true, message: "no version is installed, call hub.MustInstallV2 before cli.Main"}}
+	}
+
+	for _, version := range versions {
+		detail, err := hub.GetPluginDetail(version)
+		if err != nil {
+			issues = append(issues, lintIssue{version: version, err: true, message: err.Error()})
+			continue
+		}
+		if detail.Plugin().Desc() == "" {
+			issues = append(issues, lintIssue{version: version, message: "Desc returns empty description"})
+		}
+		if _, err := protocol.BuildDetail(version, pluginapi.DetailOptions(version, protocol.DetailOptions{})); err != nil {
+			issues = append(issues, lintIssue{version: version, err: true, message: err.Error()})
+		}
+		for _, property := range untitledProperties(detail) {
+			issues = append(issues, lintIssue{version: version, message: fmt.Sprintf("input %s has no title", property)})
+		}
+	}
+
+	return issues
+}
+
+// untitledProperties returns the sorted inputs properties without title of a
+// version whose inputs are rendered by form, which shows property names
+// instead of titles.
+func untitledProperties(detail *hub.PluginDetail) []string {
+	if !detail.FormsRenderFormEnabled() {
+		return nil
+	}
+	properties, _ := detail.InputsSchemaJSON()["properties"].(map[string]interface{})
+	form := detail.FormsRenderFormJSON()
+
+	var untitled []string
+	for name, property := range properties {
+		propertyJSON, _ := property.(map[string]interface{})
+		if title, _ := propertyJSON["title"].(string); title != "" {
+			continue
+		}
+		if attrs, ok := form[name].(map[string]interface{}); ok {
+			if title, _ := attrs["title"].(string); title != "" {
+				continue
+			}
+		}
+		untitled = append(untitled, name)
+	}
+	sort.Strings(untitled)
+	return untitled
+}
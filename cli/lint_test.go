@@ -0,0 +1,32 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	installTestVersions()
+
+	issues := "warning: version 0.1.0: Desc returns empty description\n" +
+		"error: version 0.1.0: form field polls: remote options refer to unregistered plugin api GET /polls\n" +
+		"warning: version 0.1.0: input polls has no title\n"
+
+	code, stdout, _ := run("lint")
+	assert.Equal(t, ExitFailure, code)
+	assert.Equal(t, issues+"3 issues, 1 errors\n", stdout)
+
+	code, stdout, _ = run("lint", "-strict")
+	assert.Equal(t, ExitFailure, code)
+	assert.Equal(t, issues+"3 issues, 3 errors\n", stdout)
+}
@@ -0,0 +1,396 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+)
+
+func init() {
+	register(&command{
+		name:  "new-version",
+		usage: "new-version [-from version] [-dir dir] <version>",
+		summary: "Add a version to the plugin project by copying the package of another version,\n" +
+			"the latest one by default, and installing it in main.go.",
+		standalone: true,
+		run:        runNewVersion,
+	})
+}
+
+func runNewVersion(e *env, args []string) int {
+	fs := e.flagSet(commands["new-version"])
+	from := fs.String("from", "", "version to copy, the latest version by default")
+	dir := fs.String("dir", ".", "directory of the plugin project")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return parseStatus(err)
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	written, err := newVersion(*dir, positional[0], *from)
+	if err != nil {
+		return e.errorf("new-version: %v", err)
+	}
+	for _, file := range written {
+		fmt.Fprintln(e.stdout, file)
+	}
+	return ExitOK
+}
+
+// versionPackage returns the package name of version, e.g. v110 for 1.1.0.
+func versionPackage(version string) string {
+	return "v" + strings.ReplaceAll(version, ".", "")
+}
+
+// newVersion adds version to the plugin project in root by copying the
+// package of version from under root/versions, renaming the package and the
+// version, and installing the new package in main.go after the installed
+// versions. It returns the written files.
+func newVersion(root string, version string, from string) ([]string, error) {
+	if !hub.ValidVersion(version) {
+		return nil, fmt.Errorf("%s is not a valid plugin version", version)
+	}
+
+	dirs, err := findVersionDirs(filepath.Join(root, "versions"))
+	if err != nil {
+		return nil, err
+	}
+	if _, found := dirs[version]; found {
+		return nil, fmt.Errorf("version %s already exists in %s", version, dirs[version])
+	}
+	if from == "" {
+		if len(dirs) == 0 {
+			return nil, fmt.Errorf("no version is found in %s", filepath.Join(root, "versions"))
+		}
+		for v := range dirs {
+			if from == "" || hub.CompareVersionOrder(v, from) > 0 {
+				from = v
+			}
+		}
+	}
+	srcDir, found := dirs[from]
+	if !found {
+		return nil, fmt.Errorf("version %s is not found in %s", from, filepath.Join(root, "versions"))
+	}
+	pkg := versionPackage(version)
+	for v, dir := range dirs {
+		// e.g. both 1.10.0 and 11.0.0 are in package v1100
+		if filepath.Base(dir) == pkg {
+			return nil, fmt.Errorf("package %s of version %s is already used by version %s", pkg, version, v)
+		}
+	}
+	dstDir := filepath.Join(root, "versions", pkg)
+	if _, err := os.Stat(dstDir); err == nil {
+		return nil, fmt.Errorf("%s already exists", dstDir)
+	}
+
+	// prepare all changes before writing any file
+	files, err := copyVersionDir(srcDir, dstDir, from, version)
+	if err != nil {
+		return nil, err
+	}
+	mainFile, mainSrc, err := installVersionInMain(root, filepath.Base(srcDir), filepath.Base(dstDir))
+	if err != nil {
+		return nil, err
+	}
+	files[mainFile] = mainSrc
+
+	written := make([]string, 0, len(files))
+	for file := range files {
+		written = append(written, file)
+	}
+	sort.Strings(written)
+	for _, file := range written {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(file, files[file], 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return written, nil
+}
+
+// findVersionDirs returns the package directories under dir by the version
+// returned by the Version method declared in them.
+func findVersionDirs(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := map[string]string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pkgDir := filepath.Join(dir, entry.Name())
+		files, err := filepath.Glob(filepath.Join(pkgDir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+			if err != nil {
+				return nil, err
+			}
+			if lit := versionLiteral(f); lit != nil {
+				version, _ := strconv.Unquote(lit.Value)
+				dirs[version] = pkgDir
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// versionLiteral returns the string literal returned by the Version method
+// declared in f, or nil if there is not one.
+func versionLiteral(f *ast.File) *ast.BasicLit {
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != "Version" || fn.Body == nil || len(fn.Body.List) != 1 {
+			continue
+		}
+		ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		if lit, ok := ret.Results[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			return lit
+		}
+	}
+	return nil
+}
+
+// edit replaces src[start:end] with text.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// applyEdits applies non-overlapping edits to src and formats the result.
+func applyEdits(src []byte, edits []edit) ([]byte, error) {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return format.Source(out)
+}
+
+// copyVersionDir returns the files of dst copied from src, in which the Go
+// package name and the version are renamed.
+func copyVersionDir(src string, dst string, from string, version string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(p, ".go") && filepath.Dir(rel) == "." {
+			if data, err = renameVersionFile(data, filepath.Base(src), filepath.Base(dst), from, version); err != nil {
+				return fmt.Errorf("%s: %v", p, err)
+			}
+		}
+		files[filepath.Join(dst, rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+// renameVersionFile renames package oldPkg to newPkg and version from to
+// version in Go source src. The version is renamed in the result of Version
+// method and in comments.
+func renameVersionFile(src []byte, oldPkg string, newPkg string, from string, version string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var edits []edit
+	if name := f.Name.Name; name == oldPkg || name == oldPkg+"_test" {
+		edits = append(edits, edit{offset(f.Name.Pos()), offset(f.Name.End()), newPkg + strings.TrimPrefix(name, oldPkg)})
+	}
+	if lit := versionLiteral(f); lit != nil {
+		edits = append(edits, edit{offset(lit.Pos()), offset(lit.End()), strconv.Quote(version)})
+	}
+	versionWord := regexp.MustCompile(`\b` + regexp.QuoteMeta(from) + `\b`)
+	for _, group := range f.Comments {
+		for _, comment := range group.List {
+			if versionWord.MatchString(comment.Text) {
+				edits = append(edits, edit{offset(comment.Pos()), offset(comment.End()), versionWord.ReplaceAllString(comment.Text, version)})
+			}
+		}
+	}
+	return applyEdits(src, edits)
+}
+
+// installVersionInMain finds the Go file of package main in root which
+// installs package oldPkg under versions, and returns it with the package
+// newPkg imported and installed in the same way after the last installed
+// version.
+func installVersionInMain(root string, oldPkg string, newPkg string) (string, []byte, error) {
+	files, err := filepath.Glob(filepath.Join(root, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, err
+		}
+		out, found, err := installVersion(src, oldPkg, newPkg)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %v", file, err)
+		}
+		if found {
+			return file, out, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no Go file in %s installs package %s", root, oldPkg)
+}
+
+// installVersion adds the import and installation of newPkg to src by copying
+// those of oldPkg, the bool result reports whether src installs oldPkg.
+func installVersion(src []byte, oldPkg string, newPkg string) ([]byte, bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+	if f.Name.Name != "main" {
+		return nil, false, nil
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var oldImport *ast.ImportSpec
+	hubName := "hub"
+	for _, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		switch {
+		case importPath == "github.com/TencentBlueKing/bk-plugin-framework-go/hub":
+			if spec.Name != nil {
+				hubName = spec.Name.Name
+			}
+		case path.Base(importPath) == oldPkg && path.Base(path.Dir(importPath)) == "versions":
+			oldImport = spec
+		}
+	}
+	if oldImport == nil {
+		return nil, false, nil
+	}
+	oldName := oldPkg
+	if oldImport.Name != nil {
+		oldName = oldImport.Name.Name
+	}
+	oldPath, _ := strconv.Unquote(oldImport.Path.Value)
+	newPath := path.Join(path.Dir(oldPath), newPkg)
+
+	// find the installation of oldPkg and the last installation
+	var install, last ast.Stmt
+	ast.Inspect(f, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.ExprStmt)
+		if !ok || !isInstallCall(stmt.X, hubName) {
+			return true
+		}
+		last = stmt
+		if referencesPackage(stmt, oldName) {
+			install = stmt
+		}
+		return false
+	})
+	if install == nil {
+		return nil, true, fmt.Errorf("package %s is imported but not installed with hub.MustInstallV2 or hub.MustInstall", oldPkg)
+	}
+
+	var renames []edit
+	start := offset(install.Pos())
+	ast.Inspect(install, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == oldName {
+				renames = append(renames, edit{offset(ident.Pos()) - start, offset(ident.End()) - start, newPkg})
+			}
+		}
+		return true
+	})
+	installSrc := []byte(string(src[start:offset(install.End())]))
+	sort.Slice(renames, func(i, j int) bool { return renames[i].start > renames[j].start })
+	for _, e := range renames {
+		installSrc = append(installSrc[:e.start], append([]byte(e.text), installSrc[e.end:]...)...)
+	}
+
+	importEnd := offset(oldImport.End())
+	out, err := applyEdits(src, []edit{
+		{importEnd, importEnd, fmt.Sprintf("\n%s %q", newPkg, newPath)},
+		{offset(last.End()), offset(last.End()), "\n" + string(installSrc)},
+	})
+	return out, true, err
+}
+
+// isInstallCall reports whether expr calls hub.MustInstallV2 or hub.MustInstall.
+func isInstallCall(expr ast.Expr, hubName string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == hubName && (sel.Sel.Name == "MustInstallV2" || sel.Sel.Name == "MustInstall")
+}
+
+// referencesPackage reports whether n refers to an identifier of package name.
+func referencesPackage(n ast.Node, name string) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,118 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+const templateProject = "../template/{{cookiecutter.project_name}}"
+
+// copyTemplateProject copies the project template to a temporary directory,
+// the project name is rendered as my_plugin like cookiecutter does.
+func copyTemplateProject(t *testing.T) string {
+	dst := t.TempDir()
+	err := filepath.Walk(templateProject, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(templateProject, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		data = bytes.ReplaceAll(data, []byte("{{cookiecutter.project_name}}"), []byte("my_plugin"))
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+	require.NoError(t, err)
+	return dst
+}
+
+func TestNewVersionGolden(t *testing.T) {
+	dir := copyTemplateProject(t)
+
+	code, stdout, stderr := run("new-version", "1.1.0", "-dir", dir)
+	require.Equal(t, ExitOK, code, stderr)
+
+	var written []string
+	for _, file := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		rel, err := filepath.Rel(dir, file)
+		require.NoError(t, err)
+		written = append(written, filepath.ToSlash(rel))
+	}
+	assert.Equal(t, []string{"main.go", "versions/v110/form.json", "versions/v110/plugin.go", "versions/v110/plugin_test.go"}, written)
+
+	for _, rel := range written {
+		actual, err := os.ReadFile(filepath.Join(dir, rel))
+		require.NoError(t, err)
+		golden := filepath.Join("testdata", "new-version", rel+".golden")
+		if *update {
+			require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+			require.NoError(t, os.WriteFile(golden, actual, 0o644))
+		}
+		expected, err := os.ReadFile(golden)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual), rel)
+	}
+}
+
+func TestNewVersionFrom(t *testing.T) {
+	dir := copyTemplateProject(t)
+
+	code, _, stderr := run("new-version", "-dir", dir, "1.1.0")
+	require.Equal(t, ExitOK, code, stderr)
+	code, _, stderr = run("new-version", "1.0.1", "-from", "1.0.0", "-dir", dir)
+	require.Equal(t, ExitOK, code, stderr)
+
+	data, err := os.ReadFile(filepath.Join(dir, "versions", "v101", "plugin.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "package v101\n")
+	assert.Contains(t, string(data), `return "1.0.1"`)
+
+	data, err = os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	main := string(data)
+	assert.Contains(t, main, `v101 "my_plugin/versions/v101"`)
+	assert.True(t, strings.Index(main, "&v110.Plugin{}") < strings.Index(main, "&v101.Plugin{}"), main)
+
+	code, _, stderr = run("new-version", "-dir", dir, "1.10.0")
+	require.Equal(t, ExitOK, code, stderr)
+
+	var cases = []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"1.1.0"}, "version 1.1.0 already exists in " + filepath.Join(dir, "versions", "v110")},
+		{[]string{"1.2"}, "1.2 is not a valid plugin version"},
+		{[]string{"11.0.0"}, "package v1100 of version 11.0.0 is already used by version 1.10.0"},
+		{[]string{"1.2.0", "-from", "0.9.0"}, "version 0.9.0 is not found in " + filepath.Join(dir, "versions")},
+	}
+	for _, c := range cases {
+		code, _, stderr = run(append([]string{"new-version", "-dir", dir}, c.args...)...)
+		assert.Equal(t, ExitFailure, code)
+		assert.Equal(t, "bkplugin: new-version: "+c.expected+"\n", stderr)
+	}
+}
@@ -0,0 +1,86 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Command bkplugin runs the bkplugin command line tool of a plugin project.
+//
+// Most commands operate on the plugin versions installed by the project, so
+// bkplugin builds the project to a temporary binary and runs it with
+// "bkplugin <args>", the main function of the project should call cli.Main.
+// Standalone commands such as new-version run in bkplugin directly.
+//
+// Usage:
+//
+//	bkplugin [-C dir] <command> [arguments]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/cli"
+)
+
+func main() {
+	dir := flag.String("C", ".", "directory of the plugin project")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [-C dir] <command> [arguments]\n\n", cli.Command)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) > 0 && cli.Standalone(args[0]) {
+		if err := os.Chdir(*dir); err != nil {
+			os.Exit(fail(err))
+		}
+		os.Exit(cli.Run(args, os.Stdout, os.Stderr))
+	}
+	os.Exit(run(*dir, args))
+}
+
+// run builds the plugin project in dir and runs the command line tool of it.
+func run(dir string, args []string) int {
+	tmp, err := os.MkdirTemp("", cli.Command)
+	if err != nil {
+		return fail(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	binary := filepath.Join(tmp, "plugin")
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Dir = dir
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return fail(fmt.Errorf("build plugin project %s failed: %v", dir, err))
+	}
+
+	cmd := exec.Command(binary, append([]string{cli.Command}, args...)...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		return fail(err)
+	}
+	return cli.ExitOK
+}
+
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", cli.Command, err)
+	return cli.ExitFailure
+}
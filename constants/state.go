@@ -10,6 +10,11 @@
 // Package constants define all constants relate to bk-plugin.
 package constants
 
+import (
+	"fmt"
+	"strconv"
+)
+
 type State int8
 
 // These flags define state and state's value for once bk-plugin execution.
@@ -19,4 +24,85 @@ const (
 	StateCallback State = 3
 	StateSuccess  State = 4
 	StateFail     State = 5
+	// StateCancelled is the terminal state of an execution cancelled while
+	// waiting for poll or callback.
+	StateCancelled State = 6
 )
+
+var stateNames = map[State]string{
+	StateEmpty:     "empty",
+	StatePoll:      "poll",
+	StateCallback:  "callback",
+	StateSuccess:   "success",
+	StateFail:      "fail",
+	StateCancelled: "cancelled",
+}
+
+// String returns the name of state, e.g. "poll", or "State(n)" for unknown
+// values.
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Valid reports whether s is a known state.
+func (s State) Valid() bool {
+	_, ok := stateNames[s]
+	return ok
+}
+
+// ParseState returns the state of name or number text, e.g. "poll" or "2".
+func ParseState(text string) (State, error) {
+	for s, name := range stateNames {
+		if name == text {
+			return s, nil
+		}
+	}
+	if n, err := strconv.ParseInt(text, 10, 8); err == nil && State(n).Valid() {
+		return State(n), nil
+	}
+	return 0, fmt.Errorf("invalid state %q", text)
+}
+
+// MarshalJSON encodes s as a number, which is the format of the plugin
+// protocol.
+func (s State) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(s))), nil
+}
+
+// UnmarshalJSON decodes s from a number or a name.
+func (s *State) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	state, err := ParseState(text)
+	if err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
+
+// MarshalText encodes s as its name.
+func (s State) MarshalText() ([]byte, error) {
+	if !s.Valid() {
+		return nil, fmt.Errorf("invalid state %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes s from a name or a number.
+func (s *State) UnmarshalText(text []byte) error {
+	state, err := ParseState(string(text))
+	if err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
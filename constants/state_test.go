@@ -10,9 +10,11 @@
 package constants
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStateValue(t *testing.T) {
@@ -21,4 +23,74 @@ func TestStateValue(t *testing.T) {
 	assert.Equal(t, State(3), StateCallback)
 	assert.Equal(t, State(4), StateSuccess)
 	assert.Equal(t, State(5), StateFail)
+	assert.Equal(t, State(6), StateCancelled)
+}
+
+func TestStateString(t *testing.T) {
+	assert.Equal(t, "empty", StateEmpty.String())
+	assert.Equal(t, "poll", StatePoll.String())
+	assert.Equal(t, "callback", StateCallback.String())
+	assert.Equal(t, "success", StateSuccess.String())
+	assert.Equal(t, "fail", StateFail.String())
+	assert.Equal(t, "cancelled", StateCancelled.String())
+	assert.Equal(t, "State(0)", State(0).String())
+	assert.False(t, State(0).Valid())
+}
+
+func TestParseState(t *testing.T) {
+	var cases = []struct {
+		text     string
+		expected State
+		err      string
+	}{
+		{"poll", StatePoll, ""},
+		{"4", StateSuccess, ""},
+		{"Poll", 0, `invalid state "Poll"`},
+		{"9", 0, `invalid state "9"`},
+		{"", 0, `invalid state ""`},
+	}
+
+	for _, c := range cases {
+		s, err := ParseState(c.text)
+		if c.err != "" {
+			assert.EqualError(t, err, c.err, c.text)
+			continue
+		}
+		assert.NoError(t, err, c.text)
+		assert.Equal(t, c.expected, s, c.text)
+	}
+}
+
+func TestStateJSON(t *testing.T) {
+	type trace struct {
+		State State `json:"state"`
+	}
+
+	data, err := json.Marshal(trace{State: StateCallback})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"state":3}`, string(data))
+
+	var v trace
+	require.NoError(t, json.Unmarshal([]byte(`{"state":2}`), &v))
+	assert.Equal(t, StatePoll, v.State)
+	require.NoError(t, json.Unmarshal([]byte(`{"state":"fail"}`), &v))
+	assert.Equal(t, StateFail, v.State)
+	require.NoError(t, json.Unmarshal([]byte(`{"state":null}`), &v))
+	assert.Equal(t, StateFail, v.State)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"state":"done"}`), &v), `invalid state "done"`)
+}
+
+func TestStateText(t *testing.T) {
+	text, err := StateSuccess.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "success", string(text))
+	_, err = State(0).MarshalText()
+	assert.EqualError(t, err, "invalid state 0")
+
+	var s State
+	require.NoError(t, s.UnmarshalText([]byte("callback")))
+	assert.Equal(t, StateCallback, s)
+	data, err := json.Marshal(map[State]int{StatePoll: 1})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"poll":1}`, string(data))
 }
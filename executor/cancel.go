@@ -0,0 +1,127 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	pluginruntime "github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/statemachine"
+
+	"github.com/pkg/errors"
+)
+
+// Cancel define the cancel action for an execution waiting in state, which
+// runtime should call instead of Schedule on the next invocation after the
+// execution is cancelled.
+//
+// The state must be constants.StatePoll or constants.StateCallback. The
+// runtime must implement runtime.PluginCancelRuntime, otherwise an error is
+// returned and the execution is left as it is. Executions in other states are
+// marked as failed unless they are already terminated.
+//
+// If the plugin implements kit.Canceler, its Cancel method is called with a
+// context in state, the execution is marked as failed if it returns an error.
+// Otherwise the execution is marked as constants.StateCancelled.
+func Cancel(traceID string, version string, invokeCount int, state constants.State, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
+	return CancelWithCaller(traceID, version, invokeCount, state, kit.Caller{}, reader, runtime, logger)
+}
+
+// CancelWithCaller define the cancel action with the caller who invoked this
+// execution.
+func CancelWithCaller(traceID string, version string, invokeCount int, state constants.State, caller kit.Caller, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
+	caller, tenantErr := resumeCaller(traceID, caller, runtime)
+	logger = logger.WithFields(caller.LogFields())
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := fmt.Errorf("plugin cancel panic: %v", r)
+			logger.Errorf("plugin cancel panic: %v\n", r)
+			if setErr := runtime.SetFail(traceID, panicErr); setErr != nil {
+				logger.Errorf("set fail after panic err: %v\n", setErr)
+				err = errors.Wrap(errors.Wrap(panicErr, setErr.Error()), "SetFail after Cancel panic")
+				return
+			}
+			err = panicErr
+		}
+	}()
+
+	cancelRuntime, ok := runtime.(pluginruntime.PluginCancelRuntime)
+	if !ok {
+		return errors.New("runtime does not support cancelled state")
+	}
+
+	// resume tenant of the execution
+	if tenantErr != nil {
+		logger.Errorf("resume tenant failed: %v\n", tenantErr)
+		if setErr := runtime.SetFail(traceID, tenantErr); setErr != nil {
+			return errors.Wrap(errors.Wrap(tenantErr, setErr.Error()), "SetFail after GetTenant error")
+		}
+		return tenantErr
+	}
+
+	// check cancel state
+	if err := statemachine.Transit(state, constants.StateCancelled); err != nil {
+		logger.Errorf("check cancel state failed: %v\n", err)
+		// terminal executions are left as they are
+		if !statemachine.CanTransit(state, constants.StateFail) {
+			return err
+		}
+		if setErr := runtime.SetFail(traceID, err); setErr != nil {
+			return errors.Wrap(errors.Wrap(err, setErr.Error()), "SetFail after cancel state error")
+		}
+		return err
+	}
+
+	// get plugin
+	p, err := hub.GetPlugin(version)
+	if err != nil {
+		logger.Errorf("get plugin failed: %v\n", err)
+		if setErr := runtime.SetFail(traceID, err); setErr != nil {
+			return errors.Wrap(errors.Wrap(err, setErr.Error()), "SetFail after GetPlugin error")
+		}
+		return err
+	}
+	logger.WithFields(log.Fields{
+		"plugin_version": version,
+		"invoke_count":   invokeCount,
+		"state":          state,
+	}).Info("plugin cancel start")
+
+	// call cancel hook
+	if canceler, ok := p.(kit.Canceler); ok {
+		c := kit.NewContext(traceID, state, invokeCount, reader, runtime.GetContextStore(), runtime.GetOutputsStore(), logger)
+		c.SetCaller(caller)
+		setInputsSchemas(c, version)
+
+		if err := canceler.Cancel(c); err != nil {
+			logger.Errorf("plugin cancel return err: %v\n", err)
+			if setErr := runtime.SetFail(traceID, err); setErr != nil {
+				logger.Errorf("set fail after cancel err: %v\n", setErr)
+				return errors.Wrap(errors.Wrap(err, setErr.Error()), "SetFail after Cancel error")
+			}
+			return err
+		}
+	}
+
+	if err := cancelRuntime.SetCancelled(traceID); err != nil {
+		logger.Errorf("plugin cancel success but set cancelled err: %v\n", err)
+		return err
+	}
+	logger.WithFields(log.Fields{
+		"plugin_version": version,
+		"invoke_count":   invokeCount,
+	}).Info("plugin cancel success")
+	return nil
+}
@@ -0,0 +1,121 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/statemachine"
+)
+
+type noCancelRuntime struct {
+	runtime.PluginScheduleExecuteRuntime
+}
+
+type cancelPlugin struct {
+	version string
+	err     error
+	state   *constants.State
+}
+
+func (p cancelPlugin) Version() string { return p.version }
+func (p cancelPlugin) Desc() string    { return "cancel plugin" }
+func (p cancelPlugin) Execute(c *kit.Context) error {
+	panic("execute is called")
+}
+func (p cancelPlugin) Cancel(c *kit.Context) error {
+	*p.state = c.State()
+	return p.err
+}
+
+func TestCancelCallsCancelerAndSetsCancelled(t *testing.T) {
+	var state constants.State
+	hub.MustInstallV2(cancelPlugin{version: "8.0.18", state: &state}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	err := Cancel("trace-cancel", "8.0.18", 3, constants.StateCallback, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StateCallback, state)
+	assert.True(t, rt.cancelCalled)
+	assert.False(t, rt.failCalled)
+}
+
+func TestCancelWithoutCanceler(t *testing.T) {
+	hub.MustInstallV2(waitPollPlugin{version: "8.0.19"}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	err := Cancel("trace-cancel", "8.0.19", 2, constants.StatePoll, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.NoError(t, err)
+	assert.True(t, rt.cancelCalled)
+	assert.False(t, rt.pollCalled)
+
+	rt = &testRuntime{cancelErr: fmt.Errorf("cancel write failed")}
+	err = Cancel("trace-cancel", "8.0.19", 2, constants.StatePoll, testReader{}, rt, log.WithFields(log.Fields{}))
+	assert.EqualError(t, err, "cancel write failed")
+}
+
+func TestCancelCancelerErrorSetsFail(t *testing.T) {
+	var state constants.State
+	hub.MustInstallV2(cancelPlugin{version: "8.0.20", state: &state, err: fmt.Errorf("abort job failed")}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	err := Cancel("trace-cancel", "8.0.20", 2, constants.StatePoll, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.EqualError(t, err, "abort job failed")
+	assert.Equal(t, constants.StatePoll, state)
+	assert.True(t, rt.failCalled)
+	assert.False(t, rt.cancelCalled)
+}
+
+func TestCancelRejectsIllegalState(t *testing.T) {
+	var state constants.State
+	hub.MustInstallV2(cancelPlugin{version: "8.0.21", state: &state}, hub.PluginSpec{Form: []byte(`{}`)})
+
+	// terminal executions are not marked as failed again
+	for s, failed := range map[constants.State]bool{
+		constants.StateEmpty:     true,
+		constants.StateSuccess:   false,
+		constants.StateFail:      false,
+		constants.StateCancelled: false,
+	} {
+		rt := &testRuntime{}
+		err := Cancel("trace-cancel", "8.0.21", 2, s, testReader{}, rt, log.WithFields(log.Fields{}))
+
+		assert.True(t, errors.Is(err, statemachine.ErrIllegalTransition), s.String())
+		assert.Equal(t, failed, rt.failCalled, s.String())
+		assert.False(t, rt.cancelCalled, s.String())
+	}
+	assert.Equal(t, constants.State(0), state)
+}
+
+func TestCancelRuntimeNotSupported(t *testing.T) {
+	var state constants.State
+	hub.MustInstallV2(cancelPlugin{version: "8.0.22", state: &state}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	for _, s := range []constants.State{constants.StatePoll, constants.StateSuccess} {
+		err := Cancel("trace-cancel", "8.0.22", 2, s, testReader{}, noCancelRuntime{rt}, log.WithFields(log.Fields{}))
+
+		assert.EqualError(t, err, "runtime does not support cancelled state", s.String())
+		assert.False(t, rt.failCalled, s.String())
+	}
+	assert.Equal(t, constants.State(0), state)
+}
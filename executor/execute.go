@@ -28,10 +28,21 @@ import (
 //
 // The version represent the version of plugin which will be executed.
 //
-// The reader set the read source of inputs.
+// The reader set the read source of inputs. If the reader implements
+// runtime.ScopeReader, the invoke scope is checked against hub allow scope
+// and a hub.PermissionError is returned for disallowed callers.
 //
 // The runtime set the execute runtime use in execute action.
 func Execute(traceID string, version string, reader pluginruntime.ContextReader, runtime pluginruntime.PluginExecuteRuntime, logger *log.Entry) (state constants.State, err error) {
+	return ExecuteWithCaller(traceID, version, kit.Caller{}, reader, runtime, logger)
+}
+
+// ExecuteWithCaller define the execute action with the caller of this execution.
+//
+// The caller is set to the plugin context and its fields are attached to the
+// logs of this execution.
+func ExecuteWithCaller(traceID string, version string, caller kit.Caller, reader pluginruntime.ContextReader, runtime pluginruntime.PluginExecuteRuntime, logger *log.Entry) (state constants.State, err error) {
+	logger = logger.WithFields(caller.LogFields())
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("plugin execute panic: %v", r)
@@ -48,9 +59,23 @@ func Execute(traceID string, version string, reader pluginruntime.ContextReader,
 	}
 	logger.WithField("plugin_version", version).Info("plugin execute start")
 
+	// check allow scope
+	if err := checkScope(reader, caller); err != nil {
+		logger.Errorf("check invoke scope failed: %v\n", err)
+		return constants.StateFail, err
+	}
+
+	// persist tenant for the schedule actions
+	if err := saveTenant(traceID, caller, runtime); err != nil {
+		logger.Errorf("save tenant failed: %v\n", err)
+		return constants.StateFail, err
+	}
+
 	// init context
 	c := kit.NewContext(traceID, constants.StateEmpty, 1, reader, runtime.GetContextStore(), runtime.GetOutputsStore(), logger)
+	c.SetCaller(caller)
 	setCallbackPreparer(c, traceID, version, runtime)
+	setInputsSchemas(c, version)
 
 	// execute
 	if err := p.Execute(c); err != nil {
@@ -58,6 +83,12 @@ func Execute(traceID string, version string, reader pluginruntime.ContextReader,
 		return constants.StateFail, err
 	}
 
+	// rejected wait request, e.g. both poll and callback are requested
+	if err := c.WaitError(); err != nil {
+		logger.Errorf("plugin execute wait err: %v\n", err)
+		return constants.StateFail, err
+	}
+
 	if c.WaitingCallback() {
 		logger.WithFields(log.Fields{
 			"plugin_version":           version,
@@ -10,6 +10,8 @@
 package executor
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -21,6 +23,7 @@ import (
 	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
 	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
 	"github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/statemachine"
 )
 
 type testReader struct{}
@@ -33,6 +36,15 @@ func (r testReader) ReadContextInputs(v interface{}) error {
 	return nil
 }
 
+type scopeReader struct {
+	testReader
+	scope runtime.InvokeScope
+}
+
+func (r scopeReader) ReadScope() (runtime.InvokeScope, error) {
+	return r.scope, nil
+}
+
 type testStore struct{}
 
 func (s testStore) Write(traceID string, v interface{}) error {
@@ -49,10 +61,15 @@ type testRuntime struct {
 	callbackCalled bool
 	failCalled     bool
 	successCalled  bool
+	cancelCalled   bool
+	retryCalled    bool
+	retryCount     int
 	pollErr        error
 	prepareErr     error
 	callbackErr    error
 	failErr        error
+	cancelErr      error
+	retryErr       error
 	prepared       runtime.CallbackPreparation
 }
 
@@ -89,6 +106,17 @@ func (r *testRuntime) SetSuccess(traceID string) error {
 	return nil
 }
 
+func (r *testRuntime) SetRetry(traceID string, version string, invokeCount int, state constants.State) error {
+	r.retryCalled = true
+	r.retryCount = invokeCount
+	return r.retryErr
+}
+
+func (r *testRuntime) SetCancelled(traceID string) error {
+	r.cancelCalled = true
+	return r.cancelErr
+}
+
 type panicPlugin struct {
 	version string
 }
@@ -121,6 +149,29 @@ func (p waitCallbackPlugin) Execute(c *kit.Context) error {
 	return nil
 }
 
+type waitBothPlugin struct {
+	version string
+}
+
+func (p waitBothPlugin) Version() string { return p.version }
+func (p waitBothPlugin) Desc() string    { return "wait both plugin" }
+func (p waitBothPlugin) Execute(c *kit.Context) error {
+	c.WaitPoll(time.Second)
+	c.WaitCallback(time.Hour)
+	return nil
+}
+
+type waitZeroPollPlugin struct {
+	version string
+}
+
+func (p waitZeroPollPlugin) Version() string { return p.version }
+func (p waitZeroPollPlugin) Desc() string    { return "wait zero poll plugin" }
+func (p waitZeroPollPlugin) Execute(c *kit.Context) error {
+	c.WaitPoll(0)
+	return nil
+}
+
 type prepareCallbackPlugin struct {
 	version string
 }
@@ -139,6 +190,19 @@ func (p prepareCallbackPlugin) Execute(c *kit.Context) error {
 	return nil
 }
 
+type callerPlugin struct {
+	version string
+}
+
+func (p callerPlugin) Version() string { return p.version }
+func (p callerPlugin) Desc() string    { return "caller plugin" }
+func (p callerPlugin) Execute(c *kit.Context) error {
+	if c.Caller().Operator != "admin" {
+		return fmt.Errorf("unexpected operator: %s", c.Caller().Operator)
+	}
+	return nil
+}
+
 func TestExecuteGetPluginError(t *testing.T) {
 
 }
@@ -249,3 +313,311 @@ func TestScheduleWithCallbackStateSetCallbackSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, rt.callbackCalled)
 }
+
+func TestExecuteRejectsCallerOutsideAllowScope(t *testing.T) {
+	hub.MustInstallV2(waitPollPlugin{version: "8.0.7"}, hub.PluginSpec{Form: []byte(`{}`)})
+	hub.Configure(hub.Options{
+		AllowScope: hub.AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}},
+	})
+	defer hub.Configure(hub.Options{})
+	rt := &testRuntime{}
+	reader := scopeReader{scope: runtime.InvokeScope{CallerApp: "bk_sops", ScopeType: "project", ScopeValue: "2"}}
+
+	state, err := Execute("trace-scope", "8.0.7", reader, rt, log.WithFields(log.Fields{}))
+
+	assert.Equal(t, constants.StateFail, state)
+	assert.True(t, hub.IsPermissionError(err))
+	assert.False(t, rt.pollCalled)
+}
+
+func TestExecuteAllowsCallerInAllowScope(t *testing.T) {
+	hub.MustInstallV2(waitPollPlugin{version: "8.0.8"}, hub.PluginSpec{Form: []byte(`{}`)})
+	hub.Configure(hub.Options{
+		AllowScope: hub.AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}},
+	})
+	defer hub.Configure(hub.Options{})
+	rt := &testRuntime{}
+	reader := scopeReader{scope: runtime.InvokeScope{CallerApp: "bk_sops", ScopeType: "project", ScopeValue: "1"}}
+
+	state, err := Execute("trace-scope", "8.0.8", reader, rt, log.WithFields(log.Fields{}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StatePoll, state)
+	assert.True(t, rt.pollCalled)
+}
+
+func TestExecuteWithCallerProvidesCallerToPlugin(t *testing.T) {
+	hub.MustInstallV2(callerPlugin{version: "8.0.9"}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	state, err := ExecuteWithCaller("trace-caller", "8.0.9", kit.Caller{AppCode: "bk_sops", Operator: "admin"}, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StateSuccess, state)
+}
+
+func TestExecuteWithCallerChecksScopeWithCallerApp(t *testing.T) {
+	hub.MustInstallV2(callerPlugin{version: "8.0.10"}, hub.PluginSpec{Form: []byte(`{}`)})
+	hub.Configure(hub.Options{
+		AllowScope: hub.AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}},
+	})
+	defer hub.Configure(hub.Options{})
+	reader := scopeReader{scope: runtime.InvokeScope{ScopeType: "project", ScopeValue: "2"}}
+
+	state, err := ExecuteWithCaller("trace-caller", "8.0.10", kit.Caller{AppCode: "bk_sops", Operator: "admin"}, reader, &testRuntime{}, log.WithFields(log.Fields{}))
+
+	assert.Equal(t, constants.StateFail, state)
+	assert.True(t, hub.IsPermissionError(err))
+}
+
+func TestExecuteWithCallerRejectsScopeOverridingCaller(t *testing.T) {
+	hub.MustInstallV2(callerPlugin{version: "8.0.27"}, hub.PluginSpec{Form: []byte(`{}`)})
+	hub.Configure(hub.Options{
+		AllowScope: hub.AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}},
+	})
+	defer hub.Configure(hub.Options{})
+	caller := kit.Caller{AppCode: "bk_sops", Operator: "admin", TenantID: "tenant-a"}
+
+	// the reader claims another app which is not restricted by allow scope
+	reader := scopeReader{scope: runtime.InvokeScope{CallerApp: "bk_other", ScopeType: "project", ScopeValue: "2"}}
+	state, err := ExecuteWithCaller("trace-caller", "8.0.27", caller, reader, &testRuntime{}, log.WithFields(log.Fields{}))
+	assert.Equal(t, constants.StateFail, state)
+	assert.True(t, hub.IsPermissionError(err))
+	assert.EqualError(t, err, "invoke scope caller app bk_other does not match caller bk_sops: "+
+		"app bk_sops is not allowed to invoke plugin with scope project:2")
+
+	reader = scopeReader{scope: runtime.InvokeScope{TenantID: "tenant-b", ScopeType: "project", ScopeValue: "1"}}
+	state, err = ExecuteWithCaller("trace-caller", "8.0.27", caller, reader, &testRuntime{}, log.WithFields(log.Fields{}))
+	assert.Equal(t, constants.StateFail, state)
+	assert.True(t, hub.IsPermissionError(err))
+
+	reader = scopeReader{scope: runtime.InvokeScope{TenantID: "tenant-a", CallerApp: "bk_sops", ScopeType: "project", ScopeValue: "1"}}
+	state, err = ExecuteWithCaller("trace-caller", "8.0.27", caller, reader, &testRuntime{}, log.WithFields(log.Fields{}))
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StateSuccess, state)
+}
+
+func TestScheduleWithCallerProvidesCallerToPlugin(t *testing.T) {
+	hub.MustInstallV2(callerPlugin{version: "8.0.11"}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	err := ScheduleWithCaller("trace-caller", "8.0.11", 2, constants.StatePoll, kit.Caller{Operator: "admin"}, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.NoError(t, err)
+	assert.True(t, rt.successCalled)
+	assert.False(t, rt.failCalled)
+}
+
+func TestExecuteWithCallerChecksTenantScope(t *testing.T) {
+	hub.MustInstallV2(callerPlugin{version: "8.0.12"}, hub.PluginSpec{Form: []byte(`{}`)})
+	hub.Configure(hub.Options{
+		Tenants: map[string]hub.TenantOptions{
+			"tenant-a": {AllowScope: hub.AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}}},
+		},
+	})
+	defer hub.Configure(hub.Options{})
+	reader := scopeReader{scope: runtime.InvokeScope{ScopeType: "project", ScopeValue: "2"}}
+	caller := kit.Caller{AppCode: "bk_sops", Operator: "admin", TenantID: "tenant-a"}
+
+	state, err := ExecuteWithCaller("trace-tenant", "8.0.12", caller, reader, &testRuntime{}, log.WithFields(log.Fields{}))
+	assert.Equal(t, constants.StateFail, state)
+	assert.True(t, hub.IsPermissionError(err))
+
+	caller.TenantID = "tenant-b"
+	state, err = ExecuteWithCaller("trace-tenant", "8.0.12", caller, reader, &testRuntime{}, log.WithFields(log.Fields{}))
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StateSuccess, state)
+}
+
+type jsonReader struct {
+	testReader
+	inputs string
+}
+
+func (r jsonReader) ReadInputs(v interface{}) error {
+	return json.Unmarshal([]byte(r.inputs), v)
+}
+
+type defaultsInputs struct {
+	Name  string `json:"name" jsonschema:"default=demo"`
+	Retry bool   `json:"retry" jsonschema:"default=true"`
+}
+
+type defaultsPlugin struct {
+	version string
+}
+
+func (p defaultsPlugin) Version() string { return p.version }
+func (p defaultsPlugin) Desc() string    { return "defaults plugin" }
+func (p defaultsPlugin) Execute(c *kit.Context) error {
+	var inputs defaultsInputs
+	if err := c.ReadInputs(&inputs); err != nil {
+		return err
+	}
+	if inputs != (defaultsInputs{Name: "demo", Retry: true}) {
+		return fmt.Errorf("unexpected inputs: %+v", inputs)
+	}
+	return nil
+}
+
+func TestExecuteFillsInputsDefaults(t *testing.T) {
+	hub.MustInstallV2(defaultsPlugin{version: "8.0.13"}, hub.PluginSpec{Inputs: defaultsInputs{}})
+	rt := &testRuntime{}
+
+	state, err := Execute("trace-defaults", "8.0.13", jsonReader{inputs: `{}`}, rt, log.WithFields(log.Fields{}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StateSuccess, state)
+}
+
+// An execution waits for either poll or callback. Requesting both, or
+// waiting with a non-positive interval or timeout, fails the trace even if
+// the plugin ignores the error returned by WaitPoll or WaitCallback, and
+// neither SetPoll nor SetCallback is called.
+func TestExecuteConflictingWaitFails(t *testing.T) {
+	hub.MustInstallV2(waitBothPlugin{version: "8.0.14"}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	state, err := Execute("trace-wait-both", "8.0.14", testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.EqualError(t, err, "WaitCallback is called after WaitPoll(1s), an execution can wait only once")
+	assert.Equal(t, constants.StateFail, state)
+	assert.False(t, rt.pollCalled)
+	assert.False(t, rt.callbackCalled)
+}
+
+func TestExecuteNonPositiveWaitFails(t *testing.T) {
+	hub.MustInstallV2(waitZeroPollPlugin{version: "8.0.15"}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	state, err := Execute("trace-wait-zero", "8.0.15", testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.EqualError(t, err, "WaitPoll interval must be positive, got 0s")
+	assert.Equal(t, constants.StateFail, state)
+	assert.False(t, rt.pollCalled)
+}
+
+func TestScheduleConflictingWaitSetsFail(t *testing.T) {
+	hub.MustInstallV2(waitBothPlugin{version: "8.0.16"}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	err := Schedule("trace-wait-both", "8.0.16", 2, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.EqualError(t, err, "WaitCallback is called after WaitPoll(1s), an execution can wait only once")
+	assert.True(t, rt.failCalled)
+	assert.False(t, rt.pollCalled)
+	assert.False(t, rt.callbackCalled)
+	assert.False(t, rt.successCalled)
+
+	rt = &testRuntime{failErr: fmt.Errorf("fail write failed")}
+	err = Schedule("trace-wait-both", "8.0.16", 2, testReader{}, rt, log.WithFields(log.Fields{}))
+	assert.EqualError(t, err, "SetFail after wait error: fail write failed: WaitCallback is called after WaitPoll(1s), an execution can wait only once")
+}
+
+// Only executions waiting for poll or callback can be scheduled, other resume
+// states are illegal transitions and fail the trace without invoking plugin.
+func TestScheduleRejectsIllegalResumeState(t *testing.T) {
+	hub.MustInstallV2(waitPollPlugin{version: "8.0.17"}, hub.PluginSpec{Form: []byte(`{}`)})
+
+	// only executions which can still fail are marked as failed
+	for state, failed := range map[constants.State]bool{
+		constants.StateEmpty:     true,
+		constants.StateSuccess:   false,
+		constants.StateFail:      false,
+		constants.StateCancelled: false,
+		constants.State(0):       false,
+	} {
+		rt := &testRuntime{}
+		err := ScheduleWithState("trace-illegal", "8.0.17", 2, state, testReader{}, rt, log.WithFields(log.Fields{}))
+
+		assert.True(t, errors.Is(err, statemachine.ErrIllegalTransition), state.String())
+		assert.Equal(t, failed, rt.failCalled, state.String())
+		assert.False(t, rt.pollCalled, state.String())
+	}
+
+	rt := &testRuntime{failErr: fmt.Errorf("fail write failed")}
+	err := ScheduleWithState("trace-illegal", "8.0.17", 2, constants.StateEmpty, testReader{}, rt, log.WithFields(log.Fields{}))
+	assert.EqualError(t, err, "SetFail after resume state error: fail write failed: "+
+		"illegal state transition: can not resume execution in state empty, expect poll or callback")
+}
+
+type tenantStore map[string][]byte
+
+func (s tenantStore) Write(traceID string, v interface{}) error {
+	return s.WriteTenant("", traceID, v)
+}
+
+func (s tenantStore) Read(traceID string, v interface{}) error {
+	return s.ReadTenant("", traceID, v)
+}
+
+func (s tenantStore) WriteTenant(tenantID string, traceID string, v interface{}) error {
+	data, err := json.Marshal(v)
+	s[tenantID+"/"+traceID] = data
+	return err
+}
+
+func (s tenantStore) ReadTenant(tenantID string, traceID string, v interface{}) error {
+	data, found := s[tenantID+"/"+traceID]
+	if !found {
+		return fmt.Errorf("%s of tenant %s not found", traceID, tenantID)
+	}
+	return json.Unmarshal(data, v)
+}
+
+type tenantRuntime struct {
+	testRuntime
+	store   tenantStore
+	tenants map[string]string
+}
+
+func (r *tenantRuntime) GetContextStore() runtime.ObjectStore {
+	return r.store
+}
+
+func (r *tenantRuntime) SetTenant(traceID string, tenantID string) error {
+	r.tenants[traceID] = tenantID
+	return nil
+}
+
+func (r *tenantRuntime) GetTenant(traceID string) (string, error) {
+	return r.tenants[traceID], nil
+}
+
+type tenantPollPlugin struct {
+	version string
+}
+
+func (p tenantPollPlugin) Version() string { return p.version }
+func (p tenantPollPlugin) Desc() string    { return "tenant poll plugin" }
+func (p tenantPollPlugin) Execute(c *kit.Context) error {
+	if c.State() == constants.StateEmpty {
+		c.WaitPoll(time.Second)
+		return c.Write(map[string]string{"job": "job-1"})
+	}
+	var data map[string]string
+	if err := c.Read(&data); err != nil {
+		return err
+	}
+	if data["job"] != "job-1" || c.TenantID() != "tenant-a" {
+		return fmt.Errorf("unexpected context %v of tenant %s", data, c.TenantID())
+	}
+	return nil
+}
+
+func TestScheduleResumesTenantOfExecution(t *testing.T) {
+	hub.MustInstallV2(tenantPollPlugin{version: "8.0.28"}, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &tenantRuntime{store: tenantStore{}, tenants: map[string]string{}}
+	caller := kit.Caller{Operator: "admin", TenantID: "tenant-a"}
+
+	state, err := ExecuteWithCaller("trace-tenant-poll", "8.0.28", caller, testReader{}, rt, log.WithFields(log.Fields{}))
+	assert.NoError(t, err)
+	assert.Equal(t, constants.StatePoll, state)
+	assert.Equal(t, "tenant-a", rt.tenants["trace-tenant-poll"])
+	assert.Contains(t, rt.store, "tenant-a/trace-tenant-poll")
+
+	err = Schedule("trace-tenant-poll", "8.0.28", 2, testReader{}, rt, log.WithFields(log.Fields{}))
+	assert.NoError(t, err)
+	assert.True(t, rt.successCalled)
+	assert.False(t, rt.failCalled)
+}
@@ -0,0 +1,23 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package executor
+
+import (
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+func setInputsSchemas(c *kit.Context, version string) {
+	detail, err := hub.GetPluginDetail(version)
+	if err != nil {
+		return
+	}
+	c.SetInputsSchemas(detail.InputsSchemaJSON(), detail.ContextInputsSchemaJSON())
+}
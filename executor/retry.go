@@ -0,0 +1,66 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package executor
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	pluginruntime "github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/statemachine"
+
+	"github.com/pkg/errors"
+)
+
+// Retry define the manual retry action for a failed execution, which resumes
+// the execution in state from its last persisted context.
+//
+// The invokeCount is the invoke count of the failed invocation, the plugin is
+// executed with invokeCount+1 and kit.Context.ManualRetry reports true.
+//
+// The state must be constants.StatePoll to re-run the last poll step, failed
+// callback invocations can not be retried since their payloads are gone. The
+// runtime must implement runtime.PluginRetryRuntime, the execution stays
+// failed if the state is illegal or SetRetry returns an error. After SetRetry
+// succeeds, the execution is scheduled like Schedule.
+func Retry(traceID string, version string, invokeCount int, state constants.State, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
+	return RetryWithCaller(traceID, version, invokeCount, state, kit.Caller{}, reader, runtime, logger)
+}
+
+// RetryWithCaller define the manual retry action with the caller who retries
+// this execution.
+func RetryWithCaller(traceID string, version string, invokeCount int, state constants.State, caller kit.Caller, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
+	logger = logger.WithField("manual_retry", true)
+
+	// check resume state
+	if err := statemachine.CheckRetry(state); err != nil {
+		logger.Errorf("check retry state failed: %v\n", err)
+		return err
+	}
+
+	retryRuntime, ok := runtime.(pluginruntime.PluginRetryRuntime)
+	if !ok {
+		return errors.New("runtime does not support manual retry")
+	}
+
+	invokeCount++
+	if err := retryRuntime.SetRetry(traceID, version, invokeCount, state); err != nil {
+		logger.Errorf("set retry err: %v\n", err)
+		return errors.Wrap(err, "SetRetry error")
+	}
+	logger.WithFields(log.Fields{
+		"plugin_version": version,
+		"invoke_count":   invokeCount,
+		"state":          state,
+	}).Info("plugin retry start")
+
+	return schedule(traceID, version, invokeCount, state, caller, true, reader, runtime, logger)
+}
@@ -0,0 +1,96 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/statemachine"
+)
+
+type noRetryRuntime struct {
+	runtime.PluginScheduleExecuteRuntime
+}
+
+type retryPlugin struct {
+	version string
+	context *kit.Context
+}
+
+func (p *retryPlugin) Version() string { return p.version }
+func (p *retryPlugin) Desc() string    { return "retry plugin" }
+func (p *retryPlugin) Execute(c *kit.Context) error {
+	p.context = c
+	return nil
+}
+
+func TestRetryResumesFailedExecution(t *testing.T) {
+	p := &retryPlugin{version: "8.0.23"}
+	hub.MustInstallV2(p, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	err := Retry("trace-retry", "8.0.23", 3, constants.StatePoll, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.NoError(t, err)
+	assert.True(t, rt.retryCalled)
+	assert.Equal(t, 4, rt.retryCount)
+	assert.True(t, rt.successCalled)
+	assert.Equal(t, 4, p.context.InvokeCount())
+	assert.Equal(t, constants.StatePoll, p.context.State())
+	assert.True(t, p.context.ManualRetry())
+}
+
+func TestScheduleIsNotManualRetry(t *testing.T) {
+	p := &retryPlugin{version: "8.0.24"}
+	hub.MustInstallV2(p, hub.PluginSpec{Form: []byte(`{}`)})
+
+	assert.NoError(t, Schedule("trace-retry", "8.0.24", 2, testReader{}, &testRuntime{}, log.WithFields(log.Fields{})))
+	assert.False(t, p.context.ManualRetry())
+}
+
+func TestRetryRejectsIllegalState(t *testing.T) {
+	p := &retryPlugin{version: "8.0.25"}
+	hub.MustInstallV2(p, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{}
+
+	for _, s := range []constants.State{constants.StateSuccess, constants.StateCallback} {
+		err := Retry("trace-retry", "8.0.25", 3, s, testReader{}, rt, log.WithFields(log.Fields{}))
+
+		assert.True(t, errors.Is(err, statemachine.ErrIllegalTransition), s.String())
+		assert.False(t, rt.retryCalled, s.String())
+		assert.False(t, rt.failCalled, s.String())
+		assert.Nil(t, p.context, s.String())
+	}
+}
+
+func TestRetrySetRetryError(t *testing.T) {
+	p := &retryPlugin{version: "8.0.26"}
+	hub.MustInstallV2(p, hub.PluginSpec{Form: []byte(`{}`)})
+	rt := &testRuntime{retryErr: fmt.Errorf("execution is not failed")}
+
+	err := Retry("trace-retry", "8.0.26", 3, constants.StatePoll, testReader{}, rt, log.WithFields(log.Fields{}))
+
+	assert.EqualError(t, err, "SetRetry error: execution is not failed")
+	assert.False(t, rt.failCalled)
+	assert.Nil(t, p.context)
+
+	err = Retry("trace-retry", "8.0.26", 3, constants.StatePoll, testReader{}, noRetryRuntime{rt}, log.WithFields(log.Fields{}))
+	assert.EqualError(t, err, "runtime does not support manual retry")
+	assert.Nil(t, p.context)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
 	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
 	pluginruntime "github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/statemachine"
 
 	"github.com/pkg/errors"
 )
@@ -30,13 +31,33 @@ import (
 //
 // The reader set the read source of inputs.
 //
-// The runtime set the execute runtime use in schedule action.
+// The runtime set the execute runtime use in schedule action. The tenant of
+// the execution is resumed from runtime if it implements
+// runtime.PluginTenantRuntime.
 func Schedule(traceID string, version string, invokeCount int, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
 	return ScheduleWithState(traceID, version, invokeCount, constants.StatePoll, reader, runtime, logger)
 }
 
 // ScheduleWithState define the schedule action for a specific waiting state.
+//
+// The state must be constants.StatePoll or constants.StateCallback, other
+// states are rejected with an error wrapping statemachine.ErrIllegalTransition,
+// which is also set as the fail reason of the execution.
 func ScheduleWithState(traceID string, version string, invokeCount int, state constants.State, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
+	return ScheduleWithCaller(traceID, version, invokeCount, state, kit.Caller{}, reader, runtime, logger)
+}
+
+// ScheduleWithCaller define the schedule action for a specific waiting state
+// with the caller who invoked this execution.
+func ScheduleWithCaller(traceID string, version string, invokeCount int, state constants.State, caller kit.Caller, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
+	return schedule(traceID, version, invokeCount, state, caller, false, reader, runtime, logger)
+}
+
+// schedule executes the plugin in state, manualRetry marks the execution as
+// a manual retry of a failed execution.
+func schedule(traceID string, version string, invokeCount int, state constants.State, caller kit.Caller, manualRetry bool, reader pluginruntime.ContextReader, runtime pluginruntime.PluginScheduleExecuteRuntime, logger *log.Entry) (err error) {
+	caller, tenantErr := resumeCaller(traceID, caller, runtime)
+	logger = logger.WithFields(caller.LogFields())
 	defer func() {
 		if r := recover(); r != nil {
 			panicErr := fmt.Errorf("plugin schedule panic: %v", r)
@@ -50,6 +71,28 @@ func ScheduleWithState(traceID string, version string, invokeCount int, state co
 		}
 	}()
 
+	// resume tenant of the execution
+	if tenantErr != nil {
+		logger.Errorf("resume tenant failed: %v\n", tenantErr)
+		if setErr := runtime.SetFail(traceID, tenantErr); setErr != nil {
+			return errors.Wrap(errors.Wrap(tenantErr, setErr.Error()), "SetFail after GetTenant error")
+		}
+		return tenantErr
+	}
+
+	// check resume state
+	if err := statemachine.CheckResume(state); err != nil {
+		logger.Errorf("check resume state failed: %v\n", err)
+		// terminal executions are left as they are
+		if !statemachine.CanTransit(state, constants.StateFail) {
+			return err
+		}
+		if setErr := runtime.SetFail(traceID, err); setErr != nil {
+			return errors.Wrap(errors.Wrap(err, setErr.Error()), "SetFail after resume state error")
+		}
+		return err
+	}
+
 	// get plugin
 	p, err := hub.GetPlugin(version)
 	if err != nil {
@@ -67,7 +110,10 @@ func ScheduleWithState(traceID string, version string, invokeCount int, state co
 
 	// init context
 	c := kit.NewContext(traceID, state, invokeCount, reader, runtime.GetContextStore(), runtime.GetOutputsStore(), logger)
+	c.SetCaller(caller)
+	c.SetManualRetry(manualRetry)
 	setCallbackPreparer(c, traceID, version, runtime)
+	setInputsSchemas(c, version)
 
 	// execute
 	if err := p.Execute(c); err != nil {
@@ -79,6 +125,16 @@ func ScheduleWithState(traceID string, version string, invokeCount int, state co
 		return err
 	}
 
+	// rejected wait request, e.g. both poll and callback are requested
+	if err := c.WaitError(); err != nil {
+		logger.Errorf("plugin schedule wait err: %v\n", err)
+		if setErr := runtime.SetFail(traceID, err); setErr != nil {
+			logger.Errorf("set fail after wait err: %v\n", setErr)
+			return errors.Wrap(errors.Wrap(err, setErr.Error()), "SetFail after wait error")
+		}
+		return err
+	}
+
 	if c.WaitingCallback() {
 		logger.WithFields(log.Fields{
 			"plugin_version":           version,
@@ -0,0 +1,55 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	pluginruntime "github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+)
+
+// checkScope checks the invoke scope read by reader against hub allow scope.
+//
+// The tenant id and caller app of the authenticated caller take precedence
+// over the ones in scope, which only fill them when the caller has none, and
+// a scope claiming a different tenant id or caller app is rejected.
+func checkScope(reader pluginruntime.ContextReader, caller kit.Caller) error {
+	scopeReader, ok := reader.(pluginruntime.ScopeReader)
+	if !ok {
+		return nil
+	}
+	scope, err := scopeReader.ReadScope()
+	if err != nil {
+		return err
+	}
+	if scope.TenantID, err = authenticatedValue("tenant id", scope.TenantID, caller.TenantID, scope); err != nil {
+		return err
+	}
+	if scope.CallerApp, err = authenticatedValue("caller app", scope.CallerApp, caller.AppCode, scope); err != nil {
+		return err
+	}
+	return hub.CheckTenantScope(scope.TenantID, scope.CallerApp, scope.ScopeType, scope.ScopeValue)
+}
+
+// authenticatedValue returns the authenticated value if it is not empty, or
+// the value claimed by scope otherwise. It returns an error wrapping a
+// hub.PermissionError if both are set and differ.
+func authenticatedValue(name string, claimed string, authenticated string, scope pluginruntime.InvokeScope) (string, error) {
+	if authenticated == "" {
+		return claimed, nil
+	}
+	if claimed != "" && claimed != authenticated {
+		return "", fmt.Errorf("invoke scope %s %s does not match caller %s: %w", name, claimed, authenticated,
+			&hub.PermissionError{CallerApp: authenticated, ScopeType: scope.ScopeType, ScopeValue: scope.ScopeValue})
+	}
+	return authenticated, nil
+}
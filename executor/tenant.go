@@ -0,0 +1,42 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package executor
+
+import (
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	pluginruntime "github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
+
+	"github.com/pkg/errors"
+)
+
+// saveTenant persists the tenant id of caller with the execution if runtime
+// supports it, so that the execution is resumed with the same tenant.
+func saveTenant(traceID string, caller kit.Caller, runtime pluginruntime.PluginExecuteRuntime) error {
+	tenantRuntime, ok := runtime.(pluginruntime.PluginTenantRuntime)
+	if !ok || caller.TenantID == "" {
+		return nil
+	}
+	return errors.Wrap(tenantRuntime.SetTenant(traceID, caller.TenantID), "SetTenant error")
+}
+
+// resumeCaller fills the tenant id of caller with the one persisted with the
+// execution if caller has none.
+func resumeCaller(traceID string, caller kit.Caller, runtime pluginruntime.PluginExecuteRuntime) (kit.Caller, error) {
+	tenantRuntime, ok := runtime.(pluginruntime.PluginTenantRuntime)
+	if !ok || caller.TenantID != "" {
+		return caller, nil
+	}
+	tenantID, err := tenantRuntime.GetTenant(traceID)
+	if err != nil {
+		return caller, errors.Wrap(err, "GetTenant error")
+	}
+	caller.TenantID = tenantID
+	return caller, nil
+}
@@ -1,6 +1,6 @@
 module github.com/TencentBlueKing/bk-plugin-framework-go
 
-go 1.17
+go 1.18
 
 require (
 	github.com/alecthomas/jsonschema v0.0.0-20220203024042-cc89723c9db0
@@ -0,0 +1,326 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/internal/schema"
+)
+
+// SchemaKind names one of the schemas of a plugin version.
+type SchemaKind string
+
+const (
+	SchemaInputs        SchemaKind = "inputs"
+	SchemaContextInputs SchemaKind = "context_inputs"
+	SchemaOutputs       SchemaKind = "outputs"
+)
+
+// A SchemaChange is a difference between the schemas of two plugin versions.
+//
+// Path is the dotted path of the changed property, items of arrays are
+// denoted by "[]", the path of the schema root is empty.
+type SchemaChange struct {
+	Schema   SchemaKind `json:"schema"`
+	Path     string     `json:"path"`
+	Breaking bool       `json:"breaking"`
+	Message  string     `json:"message"`
+}
+
+// Location returns the schema kind and the path of the change.
+func (c SchemaChange) Location() string {
+	if c.Path == "" {
+		return string(c.Schema)
+	}
+	return string(c.Schema) + "." + c.Path
+}
+
+func (c SchemaChange) String() string {
+	level := "compatible"
+	if c.Breaking {
+		level = "breaking"
+	}
+	return fmt.Sprintf("%s %s: %s", level, c.Location(), c.Message)
+}
+
+// A CompatReport stores the schema changes from OldVersion to NewVersion.
+type CompatReport struct {
+	OldVersion string         `json:"old_version"`
+	NewVersion string         `json:"new_version"`
+	Changes    []SchemaChange `json:"changes"`
+}
+
+// Breaking returns the breaking changes of the report.
+func (r *CompatReport) Breaking() []SchemaChange {
+	var breaking []SchemaChange
+	for _, c := range r.Changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// Compatible reports whether the report has no breaking changes.
+func (r *CompatReport) Compatible() bool {
+	return len(r.Breaking()) == 0
+}
+
+// CompareVersions compares the inputs, context inputs and outputs schemas of
+// the installed oldVersion and newVersion, see CompareSchemas.
+func CompareVersions(oldVersion string, newVersion string) (*CompatReport, error) {
+	oldDetail, err := GetPluginDetail(oldVersion)
+	if err != nil {
+		return nil, err
+	}
+	newDetail, err := GetPluginDetail(newVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CompatReport{OldVersion: oldVersion, NewVersion: newVersion}
+	report.Changes = append(report.Changes, CompareSchemas(SchemaInputs, oldDetail.InputsSchemaJSON(), newDetail.InputsSchemaJSON())...)
+	report.Changes = append(report.Changes, CompareSchemas(SchemaContextInputs, oldDetail.ContextInputsSchemaJSON(), newDetail.ContextInputsSchemaJSON())...)
+	report.Changes = append(report.Changes, CompareSchemas(SchemaOutputs, oldDetail.OutputsSchemaJSON(), newDetail.OutputsSchemaJSON())...)
+	return report, nil
+}
+
+// CompareSchemas returns the changes from the old schema to the new one.
+//
+// Inputs and context inputs are sent by callers of the plugin, so changes which
+// make the data accepted by old schema invalid are breaking: new required
+// properties, optional properties becoming required, narrowed types and
+// removed enum values. Outputs are read by callers of the plugin, so changes
+// which make the data produced by new schema unexpected are breaking: removed
+// or renamed properties, required properties becoming optional, widened types
+// and added enum values. Other changes are compatible.
+func CompareSchemas(kind SchemaKind, old map[string]interface{}, new map[string]interface{}) []SchemaChange {
+	c := &schemaComparer{kind: kind, oldRoot: old, newRoot: new, visiting: map[[2]string]bool{}}
+	c.compare("", old, new)
+	return c.changes
+}
+
+type schemaComparer struct {
+	kind     SchemaKind
+	oldRoot  map[string]interface{}
+	newRoot  map[string]interface{}
+	visiting map[[2]string]bool
+	changes  []SchemaChange
+}
+
+func (c *schemaComparer) isInputs() bool {
+	return c.kind != SchemaOutputs
+}
+
+func (c *schemaComparer) add(path string, breaking bool, format string, args ...interface{}) {
+	c.changes = append(c.changes, SchemaChange{
+		Schema:   c.kind,
+		Path:     path,
+		Breaking: breaking,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *schemaComparer) compare(path string, old map[string]interface{}, new map[string]interface{}) {
+	// recursive definitions are compared once for each pair of references
+	oldRef, _ := old["$ref"].(string)
+	newRef, _ := new["$ref"].(string)
+	if oldRef != "" && newRef != "" {
+		key := [2]string{oldRef, newRef}
+		if c.visiting[key] {
+			return
+		}
+		c.visiting[key] = true
+		defer delete(c.visiting, key)
+	}
+	old = schema.Resolve(c.oldRoot, old)
+	new = schema.Resolve(c.newRoot, new)
+
+	c.compareTypes(path, old, new)
+	c.compareEnums(path, old, new)
+	c.compareProperties(path, old, new)
+
+	oldItems, oldOK := old["items"].(map[string]interface{})
+	newItems, newOK := new["items"].(map[string]interface{})
+	if oldOK && newOK {
+		c.compare(path+"[]", oldItems, newItems)
+	}
+}
+
+func (c *schemaComparer) compareTypes(path string, old map[string]interface{}, new map[string]interface{}) {
+	oldTypes := typesOf(old)
+	newTypes := typesOf(new)
+	oldCovers := coversTypes(oldTypes, newTypes)
+	newCovers := coversTypes(newTypes, oldTypes)
+	if oldCovers && newCovers {
+		return
+	}
+
+	message := fmt.Sprintf("type changed from %s to %s", formatTypes(oldTypes), formatTypes(newTypes))
+	if c.isInputs() {
+		c.add(path, !newCovers, message)
+	} else {
+		c.add(path, !oldCovers, message)
+	}
+}
+
+func (c *schemaComparer) compareEnums(path string, old map[string]interface{}, new map[string]interface{}) {
+	oldEnum, oldOK := old["enum"].([]interface{})
+	newEnum, newOK := new["enum"].([]interface{})
+	switch {
+	case !oldOK && !newOK:
+		return
+	case !oldOK:
+		c.add(path, c.isInputs(), "values restricted to %v", newEnum)
+		return
+	case !newOK:
+		c.add(path, !c.isInputs(), "values no longer restricted to %v", oldEnum)
+		return
+	}
+
+	if removed := missingValues(oldEnum, newEnum); len(removed) > 0 {
+		c.add(path, c.isInputs(), "enum values %v removed", removed)
+	}
+	if added := missingValues(newEnum, oldEnum); len(added) > 0 {
+		c.add(path, !c.isInputs(), "enum values %v added", added)
+	}
+}
+
+func (c *schemaComparer) compareProperties(path string, old map[string]interface{}, new map[string]interface{}) {
+	oldProperties, _ := old["properties"].(map[string]interface{})
+	newProperties, _ := new["properties"].(map[string]interface{})
+	oldRequired := requiredSet(old)
+	newRequired := requiredSet(new)
+
+	names := make([]string, 0, len(oldProperties)+len(newProperties))
+	for name := range oldProperties {
+		names = append(names, name)
+	}
+	for name := range newProperties {
+		if _, found := oldProperties[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := name
+		if path != "" {
+			propertyPath = path + "." + name
+		}
+		oldProperty, inOld := oldProperties[name].(map[string]interface{})
+		newProperty, inNew := newProperties[name].(map[string]interface{})
+
+		switch {
+		case !inNew:
+			if c.isInputs() {
+				c.add(propertyPath, false, "property removed")
+			} else {
+				c.add(propertyPath, true, "property removed")
+			}
+		case !inOld:
+			if c.isInputs() && newRequired[name] {
+				c.add(propertyPath, true, "required property added")
+			} else {
+				c.add(propertyPath, false, "property added")
+			}
+		default:
+			if oldRequired[name] != newRequired[name] {
+				if newRequired[name] {
+					c.add(propertyPath, c.isInputs(), "property becomes required")
+				} else {
+					c.add(propertyPath, !c.isInputs(), "property becomes optional")
+				}
+			}
+			c.compare(propertyPath, oldProperty, newProperty)
+		}
+	}
+}
+
+// typesOf returns the types of schema s, nil means any type.
+func typesOf(s map[string]interface{}) []string {
+	switch t := s["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			types = append(types, fmt.Sprint(item))
+		}
+		sort.Strings(types)
+		return types
+	}
+	return nil
+}
+
+// coversTypes reports whether every value of types b is a value of types a.
+func coversTypes(a []string, b []string) bool {
+	if a == nil {
+		return true
+	}
+	if b == nil {
+		return false
+	}
+	for _, t := range b {
+		if !containsString(a, t) && !(t == "integer" && containsString(a, "number")) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatTypes(types []string) string {
+	if types == nil {
+		return "any"
+	}
+	return strings.Join(types, "|")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// missingValues returns the values of a which are not in b.
+func missingValues(a []interface{}, b []interface{}) []interface{} {
+	var missing []interface{}
+	for _, va := range a {
+		found := false
+		for _, vb := range b {
+			if reflect.DeepEqual(va, vb) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, va)
+		}
+	}
+	return missing
+}
+
+func requiredSet(s map[string]interface{}) map[string]bool {
+	required := map[string]bool{}
+	names, _ := s["required"].([]interface{})
+	for _, name := range names {
+		if name, ok := name.(string); ok {
+			required[name] = true
+		}
+	}
+	return required
+}
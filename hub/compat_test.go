@@ -0,0 +1,132 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CompatTestHost struct {
+	IP    string `json:"ip"`
+	Cloud int    `json:"cloud"`
+}
+
+type CompatTestOldInputs struct {
+	Name  string           `json:"name"`
+	Mode  string           `json:"mode" jsonschema:"enum=fast,enum=safe"`
+	Count int              `json:"count"`
+	Hosts []CompatTestHost `json:"hosts"`
+	Note  string           `json:"note,omitempty"`
+	Debug bool             `json:"debug"`
+}
+
+type CompatTestNewHost struct {
+	IP    string `json:"ip"`
+	Cloud string `json:"cloud"`
+}
+
+type CompatTestNewInputs struct {
+	Name    string              `json:"name"`
+	Mode    string              `json:"mode" jsonschema:"enum=fast"`
+	Count   float64             `json:"count"`
+	Hosts   []CompatTestNewHost `json:"hosts"`
+	Note    string              `json:"note"`
+	Timeout int                 `json:"timeout"`
+	Extra   string              `json:"extra,omitempty"`
+}
+
+type CompatTestOldOutputs struct {
+	Result  string `json:"result"`
+	Message string `json:"message"`
+	Code    int    `json:"code,omitempty"`
+}
+
+type CompatTestNewOutputs struct {
+	Result string  `json:"result,omitempty"`
+	Msg    string  `json:"msg"`
+	Code   float64 `json:"code,omitempty"`
+}
+
+func TestCompareVersions(t *testing.T) {
+	clearHub()
+
+	MustInstallV2(&MustInstallTestPlugin{version: "5.0.0"}, PluginSpec{Inputs: CompatTestOldInputs{}, Outputs: CompatTestOldOutputs{}})
+	MustInstallV2(&MustInstallTestPlugin{version: "5.1.0"}, PluginSpec{Inputs: CompatTestNewInputs{}, Outputs: CompatTestNewOutputs{}})
+
+	report, err := CompareVersions("5.0.0", "5.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "5.0.0", report.OldVersion)
+	assert.Equal(t, "5.1.0", report.NewVersion)
+
+	var changes []string
+	for _, c := range report.Changes {
+		changes = append(changes, c.String())
+	}
+	assert.Equal(t, []string{
+		"compatible inputs.count: type changed from integer to number",
+		"compatible inputs.debug: property removed",
+		"compatible inputs.extra: property added",
+		"breaking inputs.hosts[].cloud: type changed from integer to string",
+		"breaking inputs.mode: enum values [safe] removed",
+		"breaking inputs.note: property becomes required",
+		"breaking inputs.timeout: required property added",
+		"breaking outputs.code: type changed from integer to number",
+		"breaking outputs.message: property removed",
+		"compatible outputs.msg: property added",
+		"breaking outputs.result: property becomes optional",
+	}, changes)
+	assert.Len(t, report.Breaking(), 7)
+	assert.False(t, report.Compatible())
+
+	report, err = CompareVersions("5.1.0", "5.1.0")
+	require.NoError(t, err)
+	assert.Empty(t, report.Changes)
+	assert.True(t, report.Compatible())
+
+	_, err = CompareVersions("5.0.0", "5.2.0")
+	assert.EqualError(t, err, "can not found plugin for version: 5.2.0")
+}
+
+func TestCompareSchemasEnumsAndRecursion(t *testing.T) {
+	old := map[string]interface{}{
+		"$ref": "#/definitions/Node",
+		"definitions": map[string]interface{}{
+			"Node": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"state":    map[string]interface{}{"type": "string", "enum": []interface{}{"ok"}},
+					"children": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/definitions/Node"}},
+				},
+			},
+		},
+	}
+	new := map[string]interface{}{
+		"$ref": "#/definitions/Node",
+		"definitions": map[string]interface{}{
+			"Node": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"state":    map[string]interface{}{"type": "string", "enum": []interface{}{"ok", "failed"}},
+					"children": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/definitions/Node"}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []SchemaChange{
+		{Schema: SchemaOutputs, Path: "state", Breaking: true, Message: "enum values [failed] added"},
+	}, CompareSchemas(SchemaOutputs, old, new))
+	assert.Equal(t, []SchemaChange{
+		{Schema: SchemaInputs, Path: "state", Breaking: false, Message: "enum values [failed] added"},
+	}, CompareSchemas(SchemaInputs, old, new))
+}
@@ -0,0 +1,307 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+// FormTag is the struct tag key describing the renderform of an inputs field.
+//
+// The tag value is a comma separated list of key=value pairs, "component"
+// sets the component name, "title" and "description" set the field title and
+// description, "remote" binds the field options to a plugin API path, a
+// "required" key without value adds the required rule, and other keys are set
+// as component props. Unquoted props values of true and false are converted to
+// booleans, and values of the numeric props min, max, step, precision, rows,
+// maxlength and minlength are converted to numbers, other values are strings.
+// Values containing commas can be single quoted, quoted values are always
+// strings, and a backslash escapes the next character. A tag value of "-"
+// excludes the field from the form, e.g.
+//
+//	Biz int `json:"biz" bkform:"component=bk-select,placeholder='Select business, or type',remote=/bizs"`
+const FormTag = "bkform"
+
+// deriveForm returns the renderform derived from the bkform tags of inputs
+// struct fields, the bool result reports whether inputs has any bkform tag.
+//
+// Every property of inputsSchema gets a form field, which has type, title and
+// a default component by its type, and the required rule if the property is
+// required, tags then override them.
+func deriveForm(inputs interface{}, inputsSchema map[string]interface{}) (map[string]interface{}, bool, error) {
+	t := reflect.TypeOf(inputs)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, false, nil
+	}
+
+	tags := map[string]string{}
+	collectFormTags(t, tags)
+	if len(tags) == 0 {
+		return nil, false, nil
+	}
+
+	properties, _ := inputsSchema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if names, ok := inputsSchema["required"].([]interface{}); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	form := map[string]interface{}{}
+	for name, property := range properties {
+		if tags[name] == "-" {
+			continue
+		}
+		propertyJSON, _ := property.(map[string]interface{})
+		field, err := deriveFormField(name, propertyJSON, required[name], tags[name])
+		if err != nil {
+			return nil, true, fmt.Errorf("form field %s: %v", name, err)
+		}
+		form[name] = field
+	}
+	return form, true, nil
+}
+
+// collectFormTags stores the bkform tags of t fields by their json names,
+// fields of embedded structs are collected as the fields of t.
+func collectFormTags(t reflect.Type, tags map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jsonName := strings.Split(f.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && jsonName == "" && ft.Kind() == reflect.Struct {
+			collectFormTags(ft, tags)
+			continue
+		}
+
+		if tag, found := f.Tag.Lookup(FormTag); found {
+			if jsonName == "" {
+				jsonName = f.Name
+			}
+			tags[jsonName] = tag
+		}
+	}
+}
+
+func deriveFormField(name string, property map[string]interface{}, required bool, tag string) (map[string]interface{}, error) {
+	field := map[string]interface{}{}
+	propertyType, _ := property["type"].(string)
+	if propertyType != "" {
+		field["type"] = propertyType
+	}
+	if title, ok := property["title"].(string); ok && title != "" {
+		field["title"] = title
+	} else {
+		field["title"] = name
+	}
+	if description, ok := property["description"].(string); ok && description != "" {
+		field["description"] = description
+	}
+
+	component := map[string]interface{}{"props": map[string]interface{}{}}
+	switch propertyType {
+	case "string":
+		component["name"] = kit.ComponentInput
+	case "integer", "number":
+		component["name"] = kit.ComponentInputNumber
+	case "boolean":
+		component["name"] = kit.ComponentSwitcher
+	}
+	props := component["props"].(map[string]interface{})
+
+	rules := []interface{}{}
+	if required {
+		rules = append(rules, "required")
+	}
+
+	if tag != "" {
+		pairs, err := splitFormTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, pair := range pairs {
+			key, value := pair.key, pair.value
+			switch {
+			case key == "":
+				continue
+			case key == "required" && !pair.hasValue:
+				if !required {
+					rules = append(rules, "required")
+					required = true
+				}
+			case !pair.hasValue:
+				return nil, fmt.Errorf("bkform key %s has no value", key)
+			case key == "component":
+				component["name"] = value
+			case key == "title" || key == "description":
+				field[key] = value
+			case key == "remote":
+				for k, v := range kit.RemoteOptions(value) {
+					field[k] = v
+				}
+			case pair.quoted:
+				props[key] = value
+			default:
+				prop, err := parseFormTagValue(key, value)
+				if err != nil {
+					return nil, err
+				}
+				props[key] = prop
+			}
+		}
+	}
+
+	if _, found := component["name"]; found {
+		field["ui:component"] = component
+	}
+	if len(rules) > 0 {
+		field["ui:rules"] = rules
+	}
+	return field, nil
+}
+
+// numericFormProps are the component props whose tag values are numbers.
+var numericFormProps = map[string]bool{
+	"min":       true,
+	"max":       true,
+	"step":      true,
+	"precision": true,
+	"rows":      true,
+	"maxlength": true,
+	"minlength": true,
+}
+
+func parseFormTagValue(key string, value string) (interface{}, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if !numericFormProps[key] {
+		return value, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bkform prop %s value %s is not a number", key, value)
+	}
+	return f, nil
+}
+
+// formTagPair is a key=value pair of bkform tag.
+type formTagPair struct {
+	key      string
+	value    string
+	hasValue bool
+	quoted   bool
+}
+
+// splitFormTag splits tag into pairs by commas which are not quoted or
+// escaped, surrounding spaces of keys and unquoted values are trimmed.
+func splitFormTag(tag string) ([]formTagPair, error) {
+	var (
+		pairs   []formTagPair
+		pair    formTagPair
+		text    strings.Builder
+		inQuote bool
+		closed  bool
+	)
+	flush := func() {
+		if pair.hasValue {
+			pair.value = text.String()
+			if !pair.quoted {
+				pair.value = strings.TrimSpace(pair.value)
+			}
+		} else {
+			pair.key = strings.TrimSpace(text.String())
+		}
+		pairs = append(pairs, pair)
+		pair, inQuote, closed = formTagPair{}, false, false
+		text.Reset()
+	}
+
+	for i := 0; i < len(tag); i++ {
+		ch := tag[i]
+		switch {
+		case ch == '\\':
+			if i+1 == len(tag) {
+				return nil, fmt.Errorf("bkform tag %s ends with backslash", tag)
+			}
+			i++
+			text.WriteByte(tag[i])
+		case inQuote && ch == '\'':
+			inQuote, closed = false, true
+		case inQuote:
+			text.WriteByte(ch)
+		case ch == ',':
+			flush()
+		case closed && ch != ' ':
+			return nil, fmt.Errorf("bkform key %s has characters after quoted value", pair.key)
+		case ch == '=' && !pair.hasValue:
+			pair.key = strings.TrimSpace(text.String())
+			pair.hasValue = true
+			text.Reset()
+		case ch == '\'' && pair.hasValue && strings.TrimSpace(text.String()) == "":
+			inQuote, pair.quoted = true, true
+			text.Reset()
+		default:
+			text.WriteByte(ch)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("bkform key %s has unterminated quoted value", pair.key)
+	}
+	flush()
+	return pairs, nil
+}
+
+// mergeForm returns derived with the attributes of explicit form fields
+// overriding the derived ones.
+func mergeForm(derived map[string]interface{}, explicit map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(derived)+len(explicit))
+	for name, field := range derived {
+		merged[name] = field
+	}
+	for name, field := range explicit {
+		derivedAttrs, ok1 := merged[name].(map[string]interface{})
+		explicitAttrs, ok2 := field.(map[string]interface{})
+		if !ok1 || !ok2 {
+			merged[name] = field
+			continue
+		}
+		attrs := make(map[string]interface{}, len(derivedAttrs)+len(explicitAttrs))
+		for k, v := range derivedAttrs {
+			attrs[k] = v
+		}
+		for k, v := range explicitAttrs {
+			attrs[k] = v
+		}
+		merged[name] = attrs
+	}
+	return merged
+}
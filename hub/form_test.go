@@ -0,0 +1,166 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FormTestBase struct {
+	Operator string `json:"operator" bkform:"placeholder=Operator"`
+}
+
+type FormTestInputs struct {
+	FormTestBase
+	Name    string `json:"name" jsonschema:"title=Name"`
+	Count   int    `json:"count,omitempty" bkform:"component=bk-input-number,min=1,precision=0,required"`
+	Enabled bool   `json:"enabled,omitempty" bkform:"title=Enabled,description=Enable the task"`
+	Biz     int    `json:"biz" bkform:"component=bk-select,multiple=false,remote=/bizs"`
+	Secret  string `json:"secret,omitempty" bkform:"-"`
+	Extra   []int  `json:"extra,omitempty"`
+}
+
+func TestDeriveForm(t *testing.T) {
+	clearHub()
+
+	MustInstallV2(&MustInstallTestPlugin{version: "3.0.0"}, PluginSpec{
+		Inputs: FormTestInputs{},
+		Form:   []byte(`{"name": {"title": "Task name", "ui:component": {"name": "bk-input", "props": {"type": "textarea"}}}}`),
+	})
+
+	detail, err := GetPluginDetail("3.0.0")
+	assert.Nil(t, err)
+	assert.True(t, detail.FormsRenderFormEnabled())
+
+	expected := `{
+		"operator": {
+			"type": "string", "title": "operator",
+			"ui:component": {"name": "bk-input", "props": {"placeholder": "Operator"}},
+			"ui:rules": ["required"]
+		},
+		"name": {
+			"type": "string", "title": "Task name",
+			"ui:component": {"name": "bk-input", "props": {"type": "textarea"}},
+			"ui:rules": ["required"]
+		},
+		"count": {
+			"type": "integer", "title": "count",
+			"ui:component": {"name": "bk-input-number", "props": {"min": 1, "precision": 0}},
+			"ui:rules": ["required"]
+		},
+		"enabled": {
+			"type": "boolean", "title": "Enabled", "description": "Enable the task",
+			"ui:component": {"name": "bk-switcher", "props": {}}
+		},
+		"biz": {
+			"type": "integer", "title": "biz",
+			"ui:component": {"name": "bk-select", "props": {"multiple": false}},
+			"ui:remote": {"path": "/bizs", "method": "GET", "label_key": "label", "value_key": "value"},
+			"ui:rules": ["required"]
+		},
+		"extra": {
+			"type": "array", "title": "extra"
+		}
+	}`
+	actual, err := json.Marshal(detail.FormsRenderFormJSON())
+	assert.Nil(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func TestDeriveFormWithoutTags(t *testing.T) {
+	type Inputs struct {
+		Name string `json:"name"`
+	}
+
+	form, tagged, err := deriveForm(Inputs{}, map[string]interface{}{"properties": map[string]interface{}{"name": map[string]interface{}{}}})
+	assert.Nil(t, err)
+	assert.False(t, tagged)
+	assert.Nil(t, form)
+
+	form, tagged, err = deriveForm(nil, nil)
+	assert.Nil(t, err)
+	assert.False(t, tagged)
+	assert.Nil(t, form)
+}
+
+func TestDeriveFormInvalidTag(t *testing.T) {
+	clearHub()
+
+	type Inputs struct {
+		Name string `json:"name" bkform:"component"`
+	}
+
+	assert.PanicsWithError(t, "form field name: bkform key component has no value", func() {
+		MustInstallV2(&MustInstallTestPlugin{version: "3.0.1"}, PluginSpec{Inputs: Inputs{}})
+	})
+}
+
+func TestDeriveFormTagValues(t *testing.T) {
+	clearHub()
+
+	type Inputs struct {
+		Code  string `json:"code" bkform:"placeholder=0123,maxlength=8"`
+		Hosts string `json:"hosts" bkform:"placeholder='a, b or c',type=textarea,rows=3"`
+		Label string `json:"label" bkform:"title=Label\\, short,placeholder='true'"`
+	}
+	MustInstallV2(&MustInstallTestPlugin{version: "3.0.2"}, PluginSpec{Inputs: Inputs{}})
+
+	detail, err := GetPluginDetail("3.0.2")
+	assert.Nil(t, err)
+	form := detail.FormsRenderFormJSON()
+	props := func(name string) interface{} {
+		return form[name].(map[string]interface{})["ui:component"].(map[string]interface{})["props"]
+	}
+	assert.Equal(t, map[string]interface{}{"placeholder": "0123", "maxlength": float64(8)}, props("code"))
+	assert.Equal(t, map[string]interface{}{"placeholder": "a, b or c", "type": "textarea", "rows": float64(3)}, props("hosts"))
+	assert.Equal(t, map[string]interface{}{"placeholder": "true"}, props("label"))
+	assert.Equal(t, "Label, short", form["label"].(map[string]interface{})["title"])
+}
+
+func TestSplitFormTag(t *testing.T) {
+	var cases = []struct {
+		tag      string
+		expected []formTagPair
+		err      string
+	}{
+		{"required, min = 1", []formTagPair{{key: "required"}, {key: "min", value: "1", hasValue: true}}, ""},
+		{"a=' x, y ',b=c=d", []formTagPair{{key: "a", value: " x, y ", hasValue: true, quoted: true}, {key: "b", value: "c=d", hasValue: true}}, ""},
+		{`a='it\'s'`, []formTagPair{{key: "a", value: "it's", hasValue: true, quoted: true}}, ""},
+		{"a=x'y", []formTagPair{{key: "a", value: "x'y", hasValue: true}}, ""},
+		{"a='x", nil, "bkform key a has unterminated quoted value"},
+		{"a='x'y", nil, "bkform key a has characters after quoted value"},
+		{`a=x\`, nil, `bkform tag a=x\ ends with backslash`},
+	}
+
+	for _, c := range cases {
+		pairs, err := splitFormTag(c.tag)
+		if c.err != "" {
+			assert.EqualError(t, err, c.err, c.tag)
+			continue
+		}
+		assert.NoError(t, err, c.tag)
+		assert.Equal(t, c.expected, pairs, c.tag)
+	}
+}
+
+func TestDeriveFormInvalidNumericProp(t *testing.T) {
+	clearHub()
+
+	type Inputs struct {
+		Count int `json:"count" bkform:"min=one"`
+	}
+
+	assert.PanicsWithError(t, "form field count: bkform prop min value one is not a number", func() {
+		MustInstallV2(&MustInstallTestPlugin{version: "3.0.3"}, PluginSpec{Inputs: Inputs{}})
+	})
+}
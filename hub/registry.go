@@ -19,12 +19,13 @@ package hub
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
-
-	"github.com/alecthomas/jsonschema"
 )
 
 // emptySchema will set to plugin when the inputs or outputs schema of this plugin is empty.
@@ -33,15 +34,93 @@ var emptySchema = []byte(`{"type": "object", "properties": {}, "required": [], "
 // versionre means the valid version code regex.
 var versionRe = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9][a-z0-9]*$`)
 
+// ValidVersion reports whether v is a valid plugin version, which is in
+// major.minor.patch format and the patch may have a lower case suffix, e.g.
+// 1.0.0 and 1.0.0rc1.
+func ValidVersion(v string) bool {
+	return versionRe.MatchString(v)
+}
+
+// CompareVersionOrder compares plugin versions a and b, it returns a negative
+// number if a is older than b, a positive number if a is newer than b, and 0
+// if they are the same. Versions are compared by the numbers of their parts,
+// the suffixes of the patch are compared by their letters and then by their
+// trailing numbers, e.g. 1.0.0rc9 is older than 1.0.0rc10.
+func CompareVersionOrder(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aSuffix := splitVersionPart(aParts[i])
+		bNum, bSuffix := splitVersionPart(bParts[i])
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+		if aSuffix != bSuffix {
+			// a release is newer than its pre-releases
+			if aSuffix == "" || (bSuffix != "" && compareSuffixes(aSuffix, bSuffix) > 0) {
+				return 1
+			}
+			return -1
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+func compareSuffixes(a string, b string) int {
+	aName, aNum := splitSuffix(a)
+	bName, bNum := splitSuffix(b)
+	switch {
+	case aName != bName:
+		return strings.Compare(aName, bName)
+	case aNum != bNum:
+		return aNum - bNum
+	}
+	return strings.Compare(a, b)
+}
+
+// splitSuffix splits suffix into its letters and trailing number, e.g. rc and
+// 10 for rc10.
+func splitSuffix(suffix string) (string, int) {
+	i := len(suffix)
+	for i > 0 && suffix[i-1] >= '0' && suffix[i-1] <= '9' {
+		i--
+	}
+	num, _ := strconv.Atoi(suffix[i:])
+	return suffix[:i], num
+}
+
+func splitVersionPart(part string) (int, string) {
+	i := 0
+	for i < len(part) && part[i] >= '0' && part[i] <= '9' {
+		i++
+	}
+	num, _ := strconv.Atoi(part[:i])
+	return num, part[i:]
+}
+
 // hub will store all installed plugin detail.
 var hub = map[string]*PluginDetail{}
 
 var options = Options{}
 
 // Options stores process-level plugin framework options.
+//
+// Tenants stores the option overrides of each tenant, the process-level
+// options are used for tenants without overrides.
 type Options struct {
 	AllowScope           AllowScope
 	EnablePluginCallback bool
+	Tenants              map[string]TenantOptions
+}
+
+// TenantOptions stores the option overrides of one tenant. Nil fields fall
+// back to the process-level options.
+type TenantOptions struct {
+	AllowScope           AllowScope
+	EnablePluginCallback *bool
 }
 
 // AllowScope stores scope restrictions by plugin consumer app code.
@@ -63,6 +142,23 @@ func GetOptions() Options {
 	return options
 }
 
+// GetTenantOptions returns plugin framework options for tenantID, which
+// are the process-level options merged with the overrides of the tenant.
+func GetTenantOptions(tenantID string) Options {
+	opts := options
+	override, found := options.Tenants[tenantID]
+	if !found {
+		return opts
+	}
+	if override.AllowScope != nil {
+		opts.AllowScope = override.AllowScope
+	}
+	if override.EnablePluginCallback != nil {
+		opts.EnablePluginCallback = *override.EnablePluginCallback
+	}
+	return opts
+}
+
 // clearHub will remove all version of current bk-plugin.
 func clearHub() {
 	hub = make(map[string]*PluginDetail)
@@ -147,7 +243,8 @@ func reflectJSONSchema(object interface{}, extraAttrs map[string]map[string]inte
 	}
 
 	// generate json schema
-	reflector := jsonschema.Reflector{ExpandedStruct: true}
+	reflector := NewReflector()
+	reflector.ExpandedStruct = true
 	objectSchema, err := reflector.Reflect(object).MarshalJSON()
 	if err != nil {
 		return nil, nil, err
@@ -159,8 +256,11 @@ func reflectJSONSchema(object interface{}, extraAttrs map[string]map[string]inte
 		return nil, nil, err
 	}
 
+	definitions, _ := objectSchemaJSON["definitions"].(map[string]interface{})
+	defaultsApplied := applyTagDefaults(reflect.TypeOf(object), objectSchemaJSON, definitions, map[string]bool{})
+
 	properties := objectSchemaJSON["properties"].(map[string]interface{})
-	if extraAttrs != nil {
+	if extraAttrs != nil || defaultsApplied {
 		for prop := range extraAttrs {
 			for k, v := range extraAttrs[prop] {
 				if _, ok := properties[prop]; ok {
@@ -183,7 +283,7 @@ func reflectJSONSchema(object interface{}, extraAttrs map[string]map[string]inte
 func mustInstallDetail(p kit.Plugin, spec PluginSpec, legacyInputsFormAsSchema bool) {
 	// version validation
 	v := p.Version()
-	if !versionRe.MatchString(v) {
+	if !ValidVersion(v) {
 		panic(fmt.Errorf("%s is not a valid plugin version\n", v))
 	}
 
@@ -217,6 +317,26 @@ func mustInstallDetail(p kit.Plugin, spec PluginSpec, legacyInputsFormAsSchema b
 		}
 	}
 	formsRenderFormEnabled := !legacyInputsFormAsSchema && len(spec.Form) > 0
+	if !legacyInputsFormAsSchema {
+		derivedForm, tagged, err := deriveForm(spec.Inputs, inputsSchemaJSON)
+		if err != nil {
+			panic(err)
+		}
+		if tagged {
+			formsRenderFormJSON = mergeForm(derivedForm, formsRenderFormJSON)
+			formsRenderFormEnabled = true
+		}
+	}
+	if formsRenderFormEnabled {
+		if spec.Inputs != nil {
+			if err := validateFormFields(formsRenderFormJSON, inputsSchemaJSON); err != nil {
+				panic(err)
+			}
+		}
+		if err := validateRemoteOptions(formsRenderFormJSON); err != nil {
+			panic(err)
+		}
+	}
 	if legacyInputsFormAsSchema {
 		inputsSchema = spec.Form
 		inputsSchemaJSON = formsRenderFormJSON
@@ -235,6 +355,36 @@ func mustInstallDetail(p kit.Plugin, spec PluginSpec, legacyInputsFormAsSchema b
 	}
 }
 
+// validateFormFields checks that every form field is a property of inputs schema.
+func validateFormFields(form map[string]interface{}, inputsSchema map[string]interface{}) error {
+	properties, _ := inputsSchema["properties"].(map[string]interface{})
+	var unknown []string
+	for field := range form {
+		if _, found := properties[field]; !found {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("form fields %v are not properties of inputs schema", unknown)
+	}
+	return nil
+}
+
+// validateRemoteOptions checks the remote options sources bound to form fields.
+func validateRemoteOptions(form map[string]interface{}) error {
+	for field, attrs := range form {
+		attrsJSON, ok := attrs.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, _, err := kit.RemoteOptionsOf(attrsJSON); err != nil {
+			return fmt.Errorf("form field %s: %v", field, err)
+		}
+	}
+	return nil
+}
+
 // MustInstall will install a version of plugin to hub.
 //
 // The p is the plugin will be installed.
@@ -256,17 +406,25 @@ func MustInstall(p kit.Plugin, contextInputs interface{}, outputs interface{}, I
 
 // MustInstallV2 installs a plugin version with explicit inputs, context inputs,
 // outputs, and render form metadata.
+//
+// If spec.Inputs has bkform tags, the render form is derived from the inputs
+// schema and the tags, then the fields of spec.Form override the derived ones,
+// see FormTag.
+//
+// It panics if spec.Inputs is set and the form has fields which are not
+// properties of the inputs schema.
 func MustInstallV2(p kit.Plugin, spec PluginSpec) {
 	mustInstallDetail(p, spec, false)
 }
 
-// GetPluginVersions returns the versions of intalled plugin instance in new to old order.
+// GetPluginVersions returns the versions of intalled plugin instance in new to
+// old order, see CompareVersionOrder.
 func GetPluginVersions() []string {
 	versions := make([]string, 0, len(hub))
 	for k := range hub {
 		versions = append(versions, k)
 	}
-	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	sort.Slice(versions, func(i, j int) bool { return CompareVersionOrder(versions[i], versions[j]) > 0 })
 	return versions
 }
 
@@ -291,6 +291,48 @@ func TestMustInstallV2StoresExplicitSchemasAndForm(t *testing.T) {
 	assert.True(t, detail.FormsRenderFormEnabled())
 }
 
+func TestMustInstallV2ValidatesRemoteOptions(t *testing.T) {
+	clearHub()
+
+	form, err := json.Marshal(kit.Form{"host": kit.RemoteOptions("/hosts")})
+	assert.Nil(t, err)
+	assert.NotPanics(t, func() {
+		MustInstallV2(&MustInstallTestPlugin{version: "2.2.0"}, PluginSpec{Form: form})
+	})
+
+	form, err = json.Marshal(kit.Form{"host": kit.RemoteOptions("hosts")})
+	assert.Nil(t, err)
+	assert.PanicsWithError(t, `form field host: ui:remote path "hosts" should start with /`, func() {
+		MustInstallV2(&MustInstallTestPlugin{version: "2.2.1"}, PluginSpec{Form: form})
+	})
+}
+
+func TestMustInstallV2RejectsUnknownFormFields(t *testing.T) {
+	clearHub()
+
+	type Inputs struct {
+		TemplateID int    `json:"template_id"`
+		TaskName   string `json:"task_name"`
+	}
+
+	form := kit.NewForm().
+		Field("template_id").Input().
+		Field("task_name").Input().Textarea().
+		JSON()
+	assert.NotPanics(t, func() {
+		MustInstallV2(&MustInstallTestPlugin{version: "2.3.0"}, PluginSpec{Inputs: Inputs{}, Form: form})
+	})
+
+	form = kit.NewForm().
+		Field("template_id").Input().
+		Field("taskname").Input().
+		Field("biz").Input().
+		JSON()
+	assert.PanicsWithError(t, "form fields [biz taskname] are not properties of inputs schema", func() {
+		MustInstallV2(&MustInstallTestPlugin{version: "2.3.1"}, PluginSpec{Inputs: Inputs{}, Form: form})
+	})
+}
+
 func TestGetPluginVersions(t *testing.T) {
 	clearHub()
 	jsonData, _ := json.Marshal(map[string]string{"1": "2"})
@@ -299,8 +341,38 @@ func TestGetPluginVersions(t *testing.T) {
 	MustInstall(&MustInstallTestPlugin{version: "1.0.1"}, nil, nil, jsonBytes)
 	MustInstall(&MustInstallTestPlugin{version: "1.0.2"}, nil, nil, jsonBytes)
 	MustInstall(&MustInstallTestPlugin{version: "1.0.3"}, nil, nil, jsonBytes)
+	MustInstall(&MustInstallTestPlugin{version: "1.9.0"}, nil, nil, jsonBytes)
+	MustInstall(&MustInstallTestPlugin{version: "1.10.0"}, nil, nil, jsonBytes)
+	MustInstall(&MustInstallTestPlugin{version: "1.10.0rc1"}, nil, nil, jsonBytes)
 	versions := GetPluginVersions()
-	assert.Equal(t, []string{"1.0.3", "1.0.2", "1.0.1", "1.0.0"}, versions)
+	assert.Equal(t, []string{"1.10.0", "1.10.0rc1", "1.9.0", "1.0.3", "1.0.2", "1.0.1", "1.0.0"}, versions)
+}
+
+func TestCompareVersionOrder(t *testing.T) {
+	var cases = []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0rc1", "1.0.0", -1},
+		{"1.0.0rc2", "1.0.0rc1", 1},
+		{"1.0.0rc10", "1.0.0rc9", 1},
+		{"1.0.0b2", "1.0.0rc1", -1},
+		{"0.9.9", "1.0.0", -1},
+	}
+	for _, c := range cases {
+		actual := CompareVersionOrder(c.a, c.b)
+		switch {
+		case c.expected == 0:
+			assert.Zero(t, actual, c.a+" "+c.b)
+		case c.expected < 0:
+			assert.Negative(t, actual, c.a+" "+c.b)
+		default:
+			assert.Positive(t, actual, c.a+" "+c.b)
+		}
+	}
 }
 
 func TestGetPluginDetail(t *testing.T) {
@@ -345,3 +417,30 @@ func TestConfigureOptions(t *testing.T) {
 	assert.Equal(t, AllowScope{"bk_sops": {Type: "project", Value: []string{"1", "2"}}}, opts.AllowScope)
 	assert.True(t, opts.EnablePluginCallback)
 }
+
+func TestGetTenantOptions(t *testing.T) {
+	clearHub()
+	defer clearHub()
+
+	enabled := true
+	Configure(Options{
+		AllowScope: AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}},
+		Tenants: map[string]TenantOptions{
+			"tenant-a": {
+				AllowScope:           AllowScope{"bk_sops": {Type: "project", Value: []string{"a-*"}}},
+				EnablePluginCallback: &enabled,
+			},
+			"tenant-b": {},
+		},
+	})
+
+	opts := GetTenantOptions("tenant-a")
+	assert.Equal(t, AllowScope{"bk_sops": {Type: "project", Value: []string{"a-*"}}}, opts.AllowScope)
+	assert.True(t, opts.EnablePluginCallback)
+
+	opts = GetTenantOptions("tenant-b")
+	assert.Equal(t, AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}}, opts.AllowScope)
+	assert.False(t, opts.EnablePluginCallback)
+
+	assert.Equal(t, GetOptions(), GetTenantOptions("unknown"))
+}
@@ -0,0 +1,293 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/jsonschema"
+)
+
+// SchemaProvider is implemented by types which provide their own JSON schema,
+// e.g. types with custom JSON marshaling.
+type SchemaProvider interface {
+	JSONSchema() map[string]interface{}
+}
+
+var schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+
+var (
+	typeSchemasMu sync.RWMutex
+	typeSchemas   = map[reflect.Type]func() map[string]interface{}{}
+)
+
+// RegisterTypeSchema registers schema as the JSON schema of the type of v, for
+// types which can not implement SchemaProvider. Pass a nil pointer to
+// interface to register an interface type, e.g. (*Target)(nil).
+func RegisterTypeSchema(v interface{}, schema map[string]interface{}) {
+	registerTypeSchema(typeOf(v), func() map[string]interface{} { return schema })
+}
+
+// RegisterEnum registers values as the enum of their type, which is usually
+// the constants of a named type, e.g.
+//
+//	hub.RegisterEnum(ModeFast, ModeSafe)
+//
+// It panics if values are empty, not of the same type or not of a named type
+// declared by a package, because the enum applies to every field of the type.
+func RegisterEnum(values ...interface{}) {
+	if len(values) == 0 {
+		panic(fmt.Errorf("enum values are empty"))
+	}
+	t := reflect.TypeOf(values[0])
+	if t == nil || t.PkgPath() == "" {
+		panic(fmt.Errorf("enum type %v is not a named type, define one for the enum values", t))
+	}
+	for _, v := range values[1:] {
+		if reflect.TypeOf(v) != t {
+			panic(fmt.Errorf("enum value %v is not of type %v", v, t))
+		}
+	}
+
+	schemaType := jsonSchemaTypeOf(t)
+	if schemaType == "" {
+		panic(fmt.Errorf("enum type %v is not a string, integer, number or boolean type", t))
+	}
+	registerTypeSchema(t, func() map[string]interface{} {
+		return map[string]interface{}{"type": schemaType, "enum": values}
+	})
+}
+
+// RegisterOneOf registers the schema of an interface type as oneOf the
+// schemas of variants, so the field of the interface type accepts any of the
+// variants, e.g.
+//
+//	hub.RegisterOneOf((*Target)(nil), HostTarget{}, ModuleTarget{})
+//
+// Variant schemas are inlined, so variants should not refer to themselves.
+func RegisterOneOf(iface interface{}, variants ...interface{}) {
+	registerTypeSchema(typeOf(iface), func() map[string]interface{} {
+		oneOf := make([]interface{}, 0, len(variants))
+		for _, variant := range variants {
+			r := NewReflector()
+			r.DoNotReference = true
+			r.ExpandedStruct = reflect.TypeOf(variant).Kind() == reflect.Struct
+			schema, err := schemaToMap(r.Reflect(variant))
+			if err != nil {
+				panic(err)
+			}
+			delete(schema, "$schema")
+			delete(schema, "definitions")
+			oneOf = append(oneOf, schema)
+		}
+		return map[string]interface{}{"oneOf": oneOf}
+	})
+}
+
+func registerTypeSchema(t reflect.Type, schema func() map[string]interface{}) {
+	typeSchemasMu.Lock()
+	defer typeSchemasMu.Unlock()
+	typeSchemas[t] = schema
+}
+
+func typeOf(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface {
+		return t.Elem()
+	}
+	return t
+}
+
+// NewReflector returns a JSON schema reflector which respects SchemaProvider
+// and the registered type schemas.
+func NewReflector() *jsonschema.Reflector {
+	return &jsonschema.Reflector{TypeMapper: mapType}
+}
+
+// ReflectSchema returns the JSON schema of v in JSON format, struct types are
+// expanded as the root schema.
+//
+// Besides the keywords supported by the jsonschema struct tag, defaults of
+// boolean and number fields in the tag are reflected in their types.
+func ReflectSchema(v interface{}) (map[string]interface{}, error) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	r := NewReflector()
+	r.ExpandedStruct = t.Kind() == reflect.Struct
+
+	schema, err := schemaToMap(r.Reflect(v))
+	if err != nil {
+		return nil, err
+	}
+	definitions, _ := schema["definitions"].(map[string]interface{})
+	applyTagDefaults(t, schema, definitions, map[string]bool{})
+	return schema, nil
+}
+
+func mapType(t reflect.Type) *jsonschema.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	typeSchemasMu.RLock()
+	schema, found := typeSchemas[t]
+	typeSchemasMu.RUnlock()
+	if found {
+		return mustMapToType(schema())
+	}
+
+	if t.Kind() != reflect.Interface && reflect.PtrTo(t).Implements(schemaProviderType) {
+		provider := reflect.New(t).Interface().(SchemaProvider)
+		return mustMapToType(provider.JSONSchema())
+	}
+	return nil
+}
+
+// mustMapToType converts schema to jsonschema Type, keywords unknown to Type
+// are kept in its Extras.
+func mustMapToType(schema map[string]interface{}) *jsonschema.Type {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic(err)
+	}
+	var t jsonschema.Type
+	if err := json.Unmarshal(data, &t); err != nil {
+		panic(err)
+	}
+
+	data, err = json.Marshal(&t)
+	if err != nil {
+		panic(err)
+	}
+	var known map[string]interface{}
+	if err := json.Unmarshal(data, &known); err != nil {
+		panic(err)
+	}
+	for k, v := range schema {
+		if _, found := known[k]; !found {
+			if t.Extras == nil {
+				t.Extras = map[string]interface{}{}
+			}
+			t.Extras[k] = v
+		}
+	}
+	return &t
+}
+
+func schemaToMap(schema *jsonschema.Schema) (map[string]interface{}, error) {
+	data, err := schema.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func jsonSchemaTypeOf(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return ""
+}
+
+// applyTagDefaults sets the defaults of boolean and number fields of struct t
+// from their jsonschema tags, which are ignored or truncated to integer by
+// the reflector. It reports whether any default is set.
+func applyTagDefaults(t reflect.Type, schema map[string]interface{}, definitions map[string]interface{}, visited map[string]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			schema, _ = schema["items"].(map[string]interface{})
+		case reflect.Map:
+			patterns, _ := schema["patternProperties"].(map[string]interface{})
+			schema = nil
+			for _, pattern := range patterns {
+				schema, _ = pattern.(map[string]interface{})
+			}
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || schema == nil {
+		return false
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		schema, _ = definitions[name].(map[string]interface{})
+		if schema == nil || visited[name] {
+			return false
+		}
+		visited[name] = true
+	}
+
+	applied := false
+	properties, _ := schema["properties"].(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			applied = applyTagDefaults(f.Type, schema, definitions, visited) || applied
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		property, _ := properties[name].(map[string]interface{})
+		if property == nil {
+			continue
+		}
+
+		if value, found := tagDefault(f); found {
+			switch jsonSchemaTypeOf(f.Type) {
+			case "boolean":
+				if b, err := strconv.ParseBool(value); err == nil {
+					property["default"] = b
+					applied = true
+				}
+			case "number":
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					property["default"] = n
+					applied = true
+				}
+			}
+		}
+		applied = applyTagDefaults(f.Type, property, definitions, visited) || applied
+	}
+	return applied
+}
+
+func tagDefault(f reflect.StructField) (string, bool) {
+	for _, tag := range strings.Split(f.Tag.Get("jsonschema"), ",") {
+		if value := strings.TrimPrefix(tag, "default="); value != tag {
+			return value, true
+		}
+	}
+	return "", false
+}
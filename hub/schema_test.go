@@ -0,0 +1,155 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/internal/schema"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+type SchemaTestMode string
+
+const (
+	SchemaTestModeFast SchemaTestMode = "fast"
+	SchemaTestModeSafe SchemaTestMode = "safe"
+)
+
+type SchemaTestVersion struct {
+	Major int
+	Minor int
+}
+
+type SchemaTestTarget interface {
+	isTarget()
+}
+
+type SchemaTestHostTarget struct {
+	IP string `json:"ip"`
+}
+
+func (SchemaTestHostTarget) isTarget() {}
+
+type SchemaTestModuleTarget struct {
+	ModuleID int `json:"module_id"`
+}
+
+func (SchemaTestModuleTarget) isTarget() {}
+
+type SchemaTestOptions struct {
+	Verbose bool `json:"verbose" jsonschema:"default=true"`
+}
+
+type SchemaTestInputs struct {
+	Mode    SchemaTestMode      `json:"mode"`
+	Timeout kit.Duration        `json:"timeout" jsonschema:"title=Timeout"`
+	Version SchemaTestVersion   `json:"version"`
+	Target  SchemaTestTarget    `json:"target"`
+	Ratio   float64             `json:"ratio" jsonschema:"default=0.5,example=1"`
+	Name    string              `json:"name" jsonschema:"default=demo,example=task"`
+	Retry   bool                `json:"retry" jsonschema:"default=false"`
+	Options SchemaTestOptions   `json:"options"`
+	Backups []SchemaTestOptions `json:"backups"`
+}
+
+func TestReflectSchema(t *testing.T) {
+	RegisterEnum(SchemaTestModeFast, SchemaTestModeSafe)
+	RegisterTypeSchema(SchemaTestVersion{}, map[string]interface{}{
+		"type":     "string",
+		"pattern":  `^[0-9]+\.[0-9]+$`,
+		"x-format": "version",
+	})
+	RegisterOneOf((*SchemaTestTarget)(nil), SchemaTestHostTarget{}, SchemaTestModuleTarget{})
+
+	s, err := ReflectSchema(SchemaTestInputs{})
+	require.NoError(t, err)
+	properties := s["properties"].(map[string]interface{})
+
+	assert.Equal(t, map[string]interface{}{"type": "string", "enum": []interface{}{"fast", "safe"}}, properties["mode"])
+	assert.Equal(t, map[string]interface{}{
+		"type":     "string",
+		"title":    "Timeout",
+		"pattern":  `^(0|-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`,
+		"examples": []interface{}{"30s", "1h30m"},
+	}, properties["timeout"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "pattern": `^[0-9]+\.[0-9]+$`, "x-format": "version"}, properties["version"])
+	assert.Equal(t, map[string]interface{}{"oneOf": []interface{}{
+		map[string]interface{}{
+			"type":                 "object",
+			"properties":           map[string]interface{}{"ip": map[string]interface{}{"type": "string"}},
+			"required":             []interface{}{"ip"},
+			"additionalProperties": false,
+		},
+		map[string]interface{}{
+			"type":                 "object",
+			"properties":           map[string]interface{}{"module_id": map[string]interface{}{"type": "integer"}},
+			"required":             []interface{}{"module_id"},
+			"additionalProperties": false,
+		},
+	}}, properties["target"])
+	assert.Equal(t, map[string]interface{}{"type": "number", "default": 0.5, "examples": []interface{}{float64(1)}}, properties["ratio"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "default": "demo", "examples": []interface{}{"task"}}, properties["name"])
+	assert.Equal(t, map[string]interface{}{"type": "boolean", "default": false}, properties["retry"])
+
+	definitions := s["definitions"].(map[string]interface{})
+	options := definitions["SchemaTestOptions"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "boolean", "default": true}, options["properties"].(map[string]interface{})["verbose"])
+
+	var value interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"mode": "fast", "timeout": "1m", "version": "1.2", "target": {"module_id": 1},
+		"ratio": 1, "name": "a", "retry": true, "options": {"verbose": false}, "backups": []
+	}`), &value))
+	assert.NoError(t, schema.Validate(s, value))
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"mode": "slow", "timeout": "1m", "version": "1.2", "target": {"module_id": 1},
+		"ratio": 1, "name": "a", "retry": true, "options": {"verbose": false}, "backups": []
+	}`), &value))
+	assert.EqualError(t, schema.Validate(s, value), "mode: should be one of [fast safe]")
+}
+
+func TestMustInstallV2UsesSchemaExtensions(t *testing.T) {
+	clearHub()
+
+	type Inputs struct {
+		Timeout kit.Duration `json:"timeout"`
+		Dry     bool         `json:"dry" jsonschema:"default=true"`
+	}
+	MustInstallV2(&MustInstallTestPlugin{version: "4.0.0"}, PluginSpec{Inputs: Inputs{}})
+
+	detail, err := GetPluginDetail("4.0.0")
+	require.NoError(t, err)
+	properties := detail.InputsSchemaJSON()["properties"].(map[string]interface{})
+	assert.Equal(t, "string", properties["timeout"].(map[string]interface{})["type"])
+	assert.Equal(t, true, properties["dry"].(map[string]interface{})["default"])
+	assert.Contains(t, string(detail.InputsSchema()), `"default":true`)
+}
+
+func TestRegisterEnumPanics(t *testing.T) {
+	assert.PanicsWithError(t, "enum values are empty", func() { RegisterEnum() })
+	assert.PanicsWithError(t, "enum value 1 is not of type hub.SchemaTestMode", func() { RegisterEnum(SchemaTestModeFast, 1) })
+	assert.PanicsWithError(t, "enum type hub.SchemaTestVersion is not a string, integer, number or boolean type", func() {
+		RegisterEnum(SchemaTestVersion{})
+	})
+
+	// builtin types are shared by unrelated fields
+	assert.PanicsWithError(t, "enum type string is not a named type, define one for the enum values", func() { RegisterEnum("a", "b") })
+	assert.PanicsWithError(t, "enum type <nil> is not a named type, define one for the enum values", func() { RegisterEnum(nil) })
+	s, err := ReflectSchema(struct {
+		Name string `json:"name"`
+	}{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "string"}, s["properties"].(map[string]interface{})["name"])
+}
@@ -0,0 +1,92 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// scopeWildcard matches any scope type or scope value in a ScopeRule.
+const scopeWildcard = "*"
+
+// A PermissionError is returned when a caller app is not allowed to invoke
+// the plugin with the given scope.
+type PermissionError struct {
+	CallerApp  string
+	ScopeType  string
+	ScopeValue string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf(
+		"app %s is not allowed to invoke plugin with scope %s:%s",
+		e.CallerApp, e.ScopeType, e.ScopeValue,
+	)
+}
+
+// IsPermissionError returns whether err is or wraps a PermissionError.
+func IsPermissionError(err error) bool {
+	var permErr *PermissionError
+	return errors.As(err, &permErr)
+}
+
+// Match returns whether the rule allows scopeType and scopeValue.
+//
+// The rule type must equal scopeType unless it is "*". Each rule value is
+// matched as "*" for any value, "prefix*" for values starting with prefix,
+// or as an exact value otherwise.
+func (r ScopeRule) Match(scopeType string, scopeValue string) bool {
+	if r.Type != scopeWildcard && r.Type != scopeType {
+		return false
+	}
+	for _, pattern := range r.Value {
+		if matchScopeValue(pattern, scopeValue) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchScopeValue(pattern string, value string) bool {
+	if pattern == scopeWildcard {
+		return true
+	}
+	if strings.HasSuffix(pattern, scopeWildcard) {
+		return strings.HasPrefix(value, strings.TrimSuffix(pattern, scopeWildcard))
+	}
+	return pattern == value
+}
+
+// Check returns a PermissionError if callerApp is restricted by the allow scope
+// and scopeType and scopeValue do not match its rule. Callers without a rule
+// are allowed, which keeps the semantics of the Python plugin framework.
+func (s AllowScope) Check(callerApp string, scopeType string, scopeValue string) error {
+	rule, found := s[callerApp]
+	if !found {
+		return nil
+	}
+	if scopeType == "" || !rule.Match(scopeType, scopeValue) {
+		return &PermissionError{CallerApp: callerApp, ScopeType: scopeType, ScopeValue: scopeValue}
+	}
+	return nil
+}
+
+// CheckScope checks whether callerApp can invoke the plugin with scopeType and
+// scopeValue according to the configured Options.AllowScope.
+func CheckScope(callerApp string, scopeType string, scopeValue string) error {
+	return CheckTenantScope("", callerApp, scopeType, scopeValue)
+}
+
+// CheckTenantScope is like CheckScope but uses the allow scope of tenantID.
+func CheckTenantScope(tenantID string, callerApp string, scopeType string, scopeValue string) error {
+	return GetTenantOptions(tenantID).AllowScope.Check(callerApp, scopeType, scopeValue)
+}
@@ -0,0 +1,80 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package hub
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeRuleMatch(t *testing.T) {
+	var cases = []struct {
+		rule       ScopeRule
+		scopeType  string
+		scopeValue string
+		expected   bool
+	}{
+		{ScopeRule{Type: "project", Value: []string{"1", "2"}}, "project", "2", true},
+		{ScopeRule{Type: "project", Value: []string{"1", "2"}}, "project", "3", false},
+		{ScopeRule{Type: "project", Value: []string{"1", "2"}}, "biz", "1", false},
+		{ScopeRule{Type: "project", Value: []string{"*"}}, "project", "42", true},
+		{ScopeRule{Type: "project", Value: []string{"ops-*"}}, "project", "ops-demo", true},
+		{ScopeRule{Type: "project", Value: []string{"ops-*"}}, "project", "dev-demo", false},
+		{ScopeRule{Type: "*", Value: []string{"1"}}, "biz", "1", true},
+		{ScopeRule{Type: "project", Value: nil}, "project", "1", false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, c.rule.Match(c.scopeType, c.scopeValue), "case %+v failed", c)
+	}
+}
+
+func TestCheckScope(t *testing.T) {
+	clearHub()
+	defer clearHub()
+
+	assert.NoError(t, CheckScope("bk_sops", "project", "1"))
+
+	Configure(Options{
+		AllowScope: AllowScope{
+			"bk_sops": {Type: "project", Value: []string{"1", "2"}},
+		},
+	})
+
+	assert.NoError(t, CheckScope("bk_sops", "project", "1"))
+	assert.NoError(t, CheckScope("bk_itsm", "", ""))
+
+	err := CheckScope("bk_sops", "project", "3")
+	assert.EqualError(t, err, "app bk_sops is not allowed to invoke plugin with scope project:3")
+	assert.True(t, IsPermissionError(err))
+	assert.True(t, IsPermissionError(fmt.Errorf("invoke: %w", err)))
+
+	assert.True(t, IsPermissionError(CheckScope("bk_sops", "", "")))
+	assert.False(t, IsPermissionError(fmt.Errorf("other error")))
+}
+
+func TestCheckTenantScope(t *testing.T) {
+	clearHub()
+	defer clearHub()
+
+	Configure(Options{
+		AllowScope: AllowScope{"bk_sops": {Type: "project", Value: []string{"1"}}},
+		Tenants: map[string]TenantOptions{
+			"tenant-a": {AllowScope: AllowScope{"bk_sops": {Type: "project", Value: []string{"a-*"}}}},
+		},
+	})
+
+	assert.NoError(t, CheckTenantScope("tenant-a", "bk_sops", "project", "a-1"))
+	assert.True(t, IsPermissionError(CheckTenantScope("tenant-a", "bk_sops", "project", "1")))
+	assert.NoError(t, CheckTenantScope("tenant-b", "bk_sops", "project", "1"))
+	assert.True(t, IsPermissionError(CheckTenantScope("tenant-b", "bk_sops", "project", "a-1")))
+}
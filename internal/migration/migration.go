@@ -0,0 +1,578 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package migration checks plugin projects developed with beego-runtime for
+// the changes required by bk-plugin-runtime-go, and fixes some of them.
+package migration
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	beegoRuntimePath = "github.com/TencentBlueKing/beego-runtime"
+	oldRunnerPath    = beegoRuntimePath + "/runner"
+	newRunnerPath    = "github.com/TencentBlueKing/bk-plugin-runtime-go/runner"
+	hubPath          = "github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	pluginapiPath    = "github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+)
+
+// beegoPaths are the import path prefixes of Beego packages.
+var beegoPaths = []string{"github.com/beego/beego", "github.com/astaxie/beego"}
+
+// runtimeCommands are the process commands supported by bk-plugin-runtime-go.
+var runtimeCommands = map[string]bool{
+	"server":         true,
+	"worker":         true,
+	"syncapigw":      true,
+	"collectstatics": true,
+	"version":        true,
+}
+
+// Severity is the severity of a diagnostic. Errors must be migrated before
+// switching to bk-plugin-runtime-go, warnings are suggested changes.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Names of checks reporting diagnostics.
+const (
+	CheckRunnerImport    = "runner-import"
+	CheckRuntimeImport   = "runtime-import"
+	CheckBeegoImport     = "beego-import"
+	CheckBeegoController = "beego-controller"
+	CheckLegacyInstall   = "legacy-install"
+	CheckRegistration    = "registration"
+	CheckProcessCommand  = "process-command"
+)
+
+// inputsTypeName is the name of inputs type used to rewrite legacy
+// installations.
+const inputsTypeName = "Inputs"
+
+// A Diagnostic is a migration problem found in a plugin project.
+//
+// Fixable diagnostics can be fixed by Check with Options.Fix, and Fixed
+// reports whether it is fixed.
+type Diagnostic struct {
+	Pos      token.Position
+	Severity Severity
+	Check    string
+	Message  string
+	Fixable  bool
+	Fixed    bool
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s: %s: %s [%s]", d.Pos, d.Severity, d.Message, d.Check)
+	switch {
+	case d.Fixed:
+		s += " (fixed)"
+	case d.Fixable:
+		s += " (fixable with -fix)"
+	}
+	return s
+}
+
+// Options stores the options of Check.
+type Options struct {
+	// Fix rewrites the files to fix the fixable diagnostics.
+	Fix bool
+}
+
+// A Report stores the result of Check.
+type Report struct {
+	Diagnostics []Diagnostic
+	// FixedFiles are the files rewritten by Check.
+	FixedFiles []string
+}
+
+// Errors returns the number of unfixed error diagnostics.
+func (r *Report) Errors() int {
+	n := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError && !d.Fixed {
+			n++
+		}
+	}
+	return n
+}
+
+// Check checks the Go files and app_desc.yml of the plugin project in dir.
+func Check(dir string, opts Options) (*Report, error) {
+	modulePath, err := readModulePath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+
+		c, err := newFileChecker(dir, modulePath, p)
+		if err != nil {
+			return err
+		}
+		c.check()
+		if opts.Fix && len(c.edits) > 0 {
+			if err := c.fix(); err != nil {
+				return fmt.Errorf("fix %s: %v", p, err)
+			}
+			report.FixedFiles = append(report.FixedFiles, p)
+		}
+		report.Diagnostics = append(report.Diagnostics, c.diagnostics...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics, err := checkAppDesc(dir)
+	if err != nil {
+		return nil, err
+	}
+	report.Diagnostics = append(report.Diagnostics, diagnostics...)
+	return report, nil
+}
+
+// readModulePath returns the module path declared in dir/go.mod, or empty if
+// there is not a go.mod.
+func readModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	return "", nil
+}
+
+// edit replaces src[start:end] with text.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+type fileChecker struct {
+	root        string
+	modulePath  string
+	path        string
+	src         []byte
+	fset        *token.FileSet
+	file        *ast.File
+	imports     map[string]string // import path by local name
+	diagnostics []Diagnostic
+	edits       []edit
+}
+
+func newFileChecker(root string, modulePath string, p string) (*fileChecker, error) {
+	src, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, p, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := map[string]string{}
+	for _, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return &fileChecker{root: root, modulePath: modulePath, path: p, src: src, fset: fset, file: f, imports: imports}, nil
+}
+
+func (c *fileChecker) offset(pos token.Pos) int {
+	return c.fset.Position(pos).Offset
+}
+
+func (c *fileChecker) report(pos token.Pos, severity Severity, check string, format string, args ...interface{}) *Diagnostic {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Pos:      c.fset.Position(pos),
+		Severity: severity,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	return &c.diagnostics[len(c.diagnostics)-1]
+}
+
+// fixWith marks the last diagnostic fixable by edits.
+func (c *fileChecker) fixWith(d *Diagnostic, edits ...edit) {
+	d.Fixable = true
+	c.edits = append(c.edits, edits...)
+}
+
+func (c *fileChecker) fix() error {
+	sort.Slice(c.edits, func(i, j int) bool { return c.edits[i].start > c.edits[j].start })
+	out := append([]byte(nil), c.src...)
+	for _, e := range c.edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	out, err := format.Source(out)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, out, 0o644); err != nil {
+		return err
+	}
+	for i := range c.diagnostics {
+		if c.diagnostics[i].Fixable {
+			c.diagnostics[i].Fixed = true
+		}
+	}
+	return nil
+}
+
+// importedAs returns the local name of the import path importPath in the
+// file, or empty if it is not imported.
+func (c *fileChecker) importedAs(importPath string) string {
+	for name, p := range c.imports {
+		if p == importPath {
+			return name
+		}
+	}
+	return ""
+}
+
+func isBeegoPath(importPath string) bool {
+	for _, prefix := range beegoPaths {
+		if importPath == prefix || strings.HasPrefix(importPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fileChecker) check() {
+	c.checkImports()
+	c.checkControllers()
+	c.checkCalls()
+}
+
+func (c *fileChecker) checkImports() {
+	for _, spec := range c.file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		switch {
+		case importPath == oldRunnerPath:
+			d := c.report(spec.Path.Pos(), SeverityError, CheckRunnerImport,
+				"import %s instead of %s", newRunnerPath, oldRunnerPath)
+			c.fixWith(d, edit{c.offset(spec.Path.Pos()), c.offset(spec.Path.End()), strconv.Quote(newRunnerPath)})
+		case strings.HasPrefix(importPath, beegoRuntimePath+"/"):
+			c.report(spec.Path.Pos(), SeverityError, CheckRuntimeImport,
+				"package %s of beego-runtime is not available in bk-plugin-runtime-go, use the public APIs of bk-plugin-framework-go instead", importPath)
+		case isBeegoPath(importPath):
+			c.report(spec.Path.Pos(), SeverityError, CheckBeegoImport,
+				"Beego package %s is not available in bk-plugin-runtime-go, implement plugin APIs with pluginapi.Register", importPath)
+		}
+	}
+}
+
+// checkControllers reports struct types embedding Beego controllers.
+func (c *fileChecker) checkControllers() {
+	ast.Inspect(c.file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		for _, field := range st.Fields.List {
+			if len(field.Names) > 0 {
+				continue
+			}
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			sel, ok := typ.(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok && isBeegoPath(c.imports[pkg.Name]) && sel.Sel.Name == "Controller" {
+				c.report(spec.Pos(), SeverityError, CheckBeegoController,
+					"Beego controller %s is not supported, implement it as plugin API handlers registered with pluginapi.Register", spec.Name.Name)
+			}
+		}
+		return true
+	})
+}
+
+// checkCalls checks legacy installations and the places of registrations.
+func (c *fileChecker) checkCalls() {
+	hubName := c.importedAs(hubPath)
+	pluginapiName := c.importedAs(pluginapiPath)
+	runnerName := c.importedAs(oldRunnerPath)
+	if runnerName == "" {
+		runnerName = c.importedAs(newRunnerPath)
+	}
+
+	for _, decl := range c.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		topLevel := (fn.Name.Name == "init" || fn.Name.Name == "main") && fn.Recv == nil
+		runPos := token.NoPos
+
+		var inspect func(n ast.Node, inFuncLit bool)
+		inspect = func(n ast.Node, inFuncLit bool) {
+			ast.Inspect(n, func(n ast.Node) bool {
+				if lit, ok := n.(*ast.FuncLit); ok {
+					inspect(lit.Body, true)
+					return false
+				}
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				name := calledFunc(call)
+				if name == "" {
+					return true
+				}
+				switch {
+				case runnerName != "" && name == runnerName+".Run" && !inFuncLit && runPos == token.NoPos:
+					runPos = call.Pos()
+				case isRegistration(name, hubName, pluginapiName):
+					if !topLevel || inFuncLit {
+						c.report(call.Pos(), SeverityWarning, CheckRegistration,
+							"%s is called in %s, register plugin versions and plugin APIs in init or main", name, funcDescription(fn, inFuncLit))
+					} else if runPos != token.NoPos {
+						c.report(call.Pos(), SeverityError, CheckRegistration,
+							"%s is called after %s.Run, which blocks until the process exits", name, runnerName)
+					}
+					if hubName != "" && name == hubName+".MustInstall" {
+						c.checkLegacyInstall(call, hubName)
+					}
+				}
+				return true
+			})
+		}
+		inspect(fn.Body, false)
+	}
+}
+
+func funcDescription(fn *ast.FuncDecl, inFuncLit bool) string {
+	if inFuncLit {
+		return "a function literal of " + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+func isRegistration(name string, hubName string, pluginapiName string) bool {
+	switch {
+	case hubName != "" && (name == hubName+".MustInstall" || name == hubName+".MustInstallV2"):
+		return true
+	case pluginapiName != "" && (name == pluginapiName+".Register" || name == pluginapiName+".RegisterForVersion"):
+		return true
+	}
+	return false
+}
+
+// calledFunc returns the name of the package level function called by call,
+// e.g. hub.MustInstall.
+func calledFunc(call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return pkg.Name + "." + sel.Sel.Name
+}
+
+// checkLegacyInstall reports the hub.MustInstall call, which is fixable by
+// rewriting to hub.MustInstallV2 when the inputs type is known.
+//
+// MustInstall of beego-runtime era takes the inputs as the second argument,
+// the current one takes the plugin, context inputs, outputs and inputs form
+// and uses the form as inputs schema, whose rewriting requires an Inputs type
+// declared in the package of the plugin. The inputs form is never moved to
+// PluginSpec.Form, which takes render form metadata instead of a json schema,
+// so a non nil form is left for the user to rebuild by hand.
+func (c *fileChecker) checkLegacyInstall(call *ast.CallExpr, hubName string) {
+	d := c.report(call.Pos(), SeverityWarning, CheckLegacyInstall,
+		"%s.MustInstall uses the inputs form as inputs schema, use %s.MustInstallV2 with a PluginSpec", hubName, hubName)
+
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = string(c.src[c.offset(arg.Pos()):c.offset(arg.End())])
+	}
+
+	var fields [][2]string
+	var form string
+	switch len(args) {
+	case 5:
+		fields = [][2]string{{"Inputs", args[1]}, {"ContextInputs", args[2]}, {"Outputs", args[3]}}
+		form = args[4]
+	case 4:
+		inputs, found := c.inputsType(call.Args[0])
+		if !found {
+			d.Message += ", and set its Inputs to the inputs type of the plugin"
+			return
+		}
+		fields = [][2]string{{"Inputs", inputs}, {"ContextInputs", args[1]}, {"Outputs", args[2]}}
+		form = args[3]
+	default:
+		return
+	}
+	if form != "nil" {
+		d.Message += fmt.Sprintf(", %s is a json schema and not a render form, "+
+			"set the Form by hand or describe the fields with bkform tags", form)
+	}
+
+	var spec strings.Builder
+	fmt.Fprintf(&spec, "%s.MustInstallV2(%s, %s.PluginSpec{\n", hubName, args[0], hubName)
+	for _, field := range fields {
+		if field[1] != "nil" {
+			fmt.Fprintf(&spec, "%s: %s,\n", field[0], field[1])
+		}
+	}
+	spec.WriteString("})")
+	c.fixWith(d, edit{c.offset(call.Pos()), c.offset(call.End()), spec.String()})
+}
+
+// inputsType returns the composite literal of the Inputs type declared in the
+// package of the plugin created by expr, e.g. v100.Inputs{} for &v100.Plugin{}.
+func (c *fileChecker) inputsType(expr ast.Expr) (string, bool) {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return "", false
+	}
+
+	switch typ := lit.Type.(type) {
+	case *ast.Ident:
+		if declaresType(filepath.Dir(c.path), inputsTypeName) {
+			return inputsTypeName + "{}", true
+		}
+	case *ast.SelectorExpr:
+		pkg, ok := typ.X.(*ast.Ident)
+		if !ok || c.modulePath == "" {
+			return "", false
+		}
+		importPath := c.imports[pkg.Name]
+		if !strings.HasPrefix(importPath, c.modulePath+"/") {
+			return "", false
+		}
+		dir := filepath.Join(c.root, filepath.FromSlash(strings.TrimPrefix(importPath, c.modulePath+"/")))
+		if declaresType(dir, inputsTypeName) {
+			return pkg.Name + "." + inputsTypeName + "{}", true
+		}
+	}
+	return "", false
+}
+
+// declaresType reports whether the package in dir declares type name.
+func declaresType(dir string, name string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if spec.(*ast.TypeSpec).Name.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+var commandRe = regexp.MustCompile(`^(\s*-?\s*command:\s*)(.+?)\s*$`)
+
+// checkAppDesc checks the process commands in dir/app_desc.yml.
+func checkAppDesc(dir string) ([]Diagnostic, error) {
+	p := filepath.Join(dir, "app_desc.yml")
+	data, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		m := commandRe.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		fields := strings.Fields(strings.Trim(m[2], `"'`))
+		if len(fields) < 2 || runtimeCommands[fields[1]] {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Pos:      token.Position{Filename: p, Line: line, Column: len(m[1]) + 1},
+			Severity: SeverityError,
+			Check:    CheckProcessCommand,
+			Message:  fmt.Sprintf("process command %q is not supported by bk-plugin-runtime-go, use one of server, worker, syncapigw, collectstatics and version", fields[1]),
+		})
+	}
+	return diagnostics, scanner.Err()
+}
@@ -0,0 +1,148 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyLegacyProject copies testdata/legacy to a temporary directory.
+func copyLegacyProject(t *testing.T) string {
+	src := filepath.Join("testdata", "legacy")
+	dst := t.TempDir()
+	err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+	require.NoError(t, err)
+	return dst
+}
+
+func diagnosticStrings(dir string, report *Report) []string {
+	var lines []string
+	for _, d := range report.Diagnostics {
+		lines = append(lines, strings.TrimPrefix(d.String(), dir+string(filepath.Separator)))
+	}
+	return lines
+}
+
+func TestCheck(t *testing.T) {
+	dir := copyLegacyProject(t)
+
+	report, err := Check(dir, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`api/api.go:4:2: error: package github.com/TencentBlueKing/beego-runtime/conf of beego-runtime is not available in bk-plugin-runtime-go, use the public APIs of bk-plugin-framework-go instead [runtime-import]`,
+		`api/api.go:6:2: error: Beego package github.com/beego/beego/v2/server/web is not available in bk-plugin-runtime-go, implement plugin APIs with pluginapi.Register [beego-import]`,
+		`api/api.go:9:6: error: Beego controller HostsController is not supported, implement it as plugin API handlers registered with pluginapi.Register [beego-controller]`,
+		`api/api.go:15:2: warning: pluginapi.Register is called in Setup, register plugin versions and plugin APIs in init or main [registration]`,
+		`main.go:6:2: error: import github.com/TencentBlueKing/bk-plugin-runtime-go/runner instead of github.com/TencentBlueKing/beego-runtime/runner [runner-import] (fixable with -fix)`,
+		`main.go:11:2: warning: hub.MustInstall uses the inputs form as inputs schema, use hub.MustInstallV2 with a PluginSpec, v100.InputsForm is a json schema and not a render form, set the Form by hand or describe the fields with bkform tags [legacy-install] (fixable with -fix)`,
+		`main.go:15:2: warning: hub.MustInstall uses the inputs form as inputs schema, use hub.MustInstallV2 with a PluginSpec, and set its Inputs to the inputs type of the plugin [legacy-install]`,
+		`main.go:17:2: error: hub.MustInstallV2 is called after runner.Run, which blocks until the process exits [registration]`,
+		`app_desc.yml:10:18: error: process command "debugpanel" is not supported by bk-plugin-runtime-go, use one of server, worker, syncapigw, collectstatics and version [process-command]`,
+	}, diagnosticStrings(dir, report))
+	assert.Equal(t, 6, report.Errors())
+	assert.Empty(t, report.FixedFiles)
+}
+
+func TestCheckFix(t *testing.T) {
+	dir := copyLegacyProject(t)
+
+	report, err := Check(dir, Options{Fix: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "main.go")}, report.FixedFiles)
+	assert.Equal(t, 5, report.Errors())
+
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, `package main
+
+import (
+	v100 "example.com/legacy/versions/v100"
+	v200 "example.com/legacy/versions/v200"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-runtime-go/runner"
+)
+
+func init() {
+	hub.MustInstallV2(&v100.Plugin{}, hub.PluginSpec{
+		Inputs:        v100.Inputs{},
+		ContextInputs: v100.ContextInputs{},
+		Outputs:       v100.Outputs{},
+	})
+}
+
+func main() {
+	hub.MustInstall(&v200.Plugin{}, v200.ContextInputs{}, nil, v200.InputsForm)
+	runner.Run()
+	hub.MustInstallV2(&v100.Plugin{}, hub.PluginSpec{})
+}
+`, string(data))
+
+	// fixed problems are not reported again
+	report, err = Check(dir, Options{})
+	require.NoError(t, err)
+	for _, d := range report.Diagnostics {
+		assert.False(t, d.Fixable, d.String())
+	}
+}
+
+// legacyInputs and legacyInputsForm mirror the v100 version of testdata/legacy.
+type legacyInputs struct {
+	Host string `json:"host"`
+}
+
+var legacyInputsForm = []byte(`{"type": "object", "properties": {"host": {"type": "string", "title": "Host"}}, "required": ["host"]}`)
+
+type legacyPlugin struct {
+	version string
+}
+
+func (p *legacyPlugin) Version() string            { return p.version }
+func (p *legacyPlugin) Desc() string               { return "legacy plugin" }
+func (p *legacyPlugin) Execute(*kit.Context) error { return nil }
+
+func TestCheckFixInstalls(t *testing.T) {
+	// the fixed install of v100
+	assert.NotPanics(t, func() {
+		hub.MustInstallV2(&legacyPlugin{version: "1.0.0"}, hub.PluginSpec{Inputs: legacyInputs{}})
+	})
+	detail, err := hub.GetPluginDetail("1.0.0")
+	require.NoError(t, err)
+	assert.Contains(t, detail.InputsSchemaJSON()["properties"], "host")
+
+	// the legacy inputs form is not a render form
+	assert.Panics(t, func() {
+		hub.MustInstallV2(&legacyPlugin{version: "1.0.1"}, hub.PluginSpec{Inputs: legacyInputs{}, Form: legacyInputsForm})
+	})
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github.com/TencentBlueKing/beego-runtime/conf"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+	"github.com/beego/beego/v2/server/web"
+)
+
+type HostsController struct {
+	web.Controller
+}
+
+func Setup() {
+	_ = conf.Settings
+	pluginapi.Register(nil)
+}
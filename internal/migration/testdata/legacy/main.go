@@ -0,0 +1,18 @@
+package main
+
+import (
+	v100 "example.com/legacy/versions/v100"
+	v200 "example.com/legacy/versions/v200"
+	"github.com/TencentBlueKing/beego-runtime/runner"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+)
+
+func init() {
+	hub.MustInstall(&v100.Plugin{}, v100.ContextInputs{}, v100.Outputs{}, v100.InputsForm)
+}
+
+func main() {
+	hub.MustInstall(&v200.Plugin{}, v200.ContextInputs{}, nil, v200.InputsForm)
+	runner.Run()
+	hub.MustInstallV2(&v100.Plugin{}, hub.PluginSpec{})
+}
@@ -0,0 +1,11 @@
+package v100
+
+type Inputs struct {
+	Host string `json:"host"`
+}
+type ContextInputs struct{}
+type Outputs struct{}
+
+var InputsForm = []byte(`{"type": "object", "properties": {"host": {"type": "string", "title": "Host"}}, "required": ["host"]}`)
+
+type Plugin struct{}
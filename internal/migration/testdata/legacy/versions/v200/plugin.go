@@ -0,0 +1,7 @@
+package v200
+
+type ContextInputs struct{}
+
+var InputsForm []byte
+
+type Plugin struct{}
@@ -0,0 +1,148 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// HasDefaults reports whether any schema in s, including its definitions,
+// declares a default value.
+func HasDefaults(s interface{}) bool {
+	switch s := s.(type) {
+	case map[string]interface{}:
+		if _, found := s["default"]; found {
+			return true
+		}
+		for key, sub := range s {
+			if key == "enum" || key == "examples" || key == "const" {
+				continue
+			}
+			if HasDefaults(sub) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, sub := range s {
+			if HasDefaults(sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ApplyDefaults fills the defaults declared by root into value and returns
+// the filled value, value is modified in place.
+//
+// Missing properties of objects get the defaults of their schemas, then
+// properties, pattern properties, additional properties and array items are
+// filled recursively. For anyOf and oneOf, the defaults of the first schema
+// matching value are applied. A nil value is filled as an empty object if
+// root is an object schema.
+func ApplyDefaults(root map[string]interface{}, value interface{}) interface{} {
+	if value == nil && root["type"] == "object" {
+		value = map[string]interface{}{}
+	}
+	return defaulter{validator{root: root}}.apply(root, value)
+}
+
+type defaulter struct {
+	validator validator
+}
+
+func (d defaulter) apply(s map[string]interface{}, value interface{}) interface{} {
+	s = Resolve(d.validator.root, s)
+
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				value = d.apply(subSchema, value)
+			}
+		}
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		schemas, _ := s[keyword].([]interface{})
+		for _, sub := range schemas {
+			subSchema, ok := sub.(map[string]interface{})
+			if ok && d.validator.validate(subSchema, value, rootPath) == nil {
+				value = d.apply(subSchema, value)
+				break
+			}
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		d.applyObject(s, value)
+	case []interface{}:
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			for i := range value {
+				value[i] = d.apply(items, value[i])
+			}
+		}
+	}
+	return value
+}
+
+func (d defaulter) applyObject(s map[string]interface{}, value map[string]interface{}) {
+	properties, _ := s["properties"].(map[string]interface{})
+	for key, sub := range properties {
+		property, ok := sub.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		property = Resolve(d.validator.root, property)
+		if _, found := value[key]; !found {
+			defaultValue, hasDefault := property["default"]
+			if !hasDefault {
+				continue
+			}
+			value[key] = copyValue(defaultValue)
+		}
+		value[key] = d.apply(property, value[key])
+	}
+
+	patternProperties, _ := s["patternProperties"].(map[string]interface{})
+	for key := range value {
+		if _, found := properties[key]; found {
+			continue
+		}
+		matched := false
+		for pattern, sub := range patternProperties {
+			re, err := regexp.Compile(pattern)
+			if err != nil || !re.MatchString(key) {
+				continue
+			}
+			matched = true
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				value[key] = d.apply(subSchema, value[key])
+			}
+		}
+		if additional, ok := s["additionalProperties"].(map[string]interface{}); ok && !matched {
+			value[key] = d.apply(additional, value[key])
+		}
+	}
+}
+
+// copyValue returns a deep copy of a JSON value, so filled defaults do not
+// share maps and slices with the schema.
+func copyValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var copied interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return v
+	}
+	return copied
+}
@@ -0,0 +1,89 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	root := mustJSON(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "default": "demo"},
+			"count": {"type": "integer", "default": 1},
+			"options": {"$ref": "#/definitions/Options"},
+			"hosts": {"type": "array", "items": {"$ref": "#/definitions/Host"}},
+			"labels": {"type": "object", "patternProperties": {".*": {"$ref": "#/definitions/Host"}}},
+			"tags": {"type": "array", "default": ["a"]},
+			"target": {"oneOf": [
+				{"type": "object", "required": ["ip"], "properties": {"ip": {"type": "string"}, "port": {"type": "integer", "default": 22}}},
+				{"type": "object", "required": ["module"], "properties": {"module": {"type": "string"}, "depth": {"type": "integer", "default": 1}}}
+			]}
+		},
+		"definitions": {
+			"Options": {"type": "object", "properties": {"verbose": {"type": "boolean", "default": false}}, "default": {}},
+			"Host": {"type": "object", "properties": {"ip": {"type": "string"}, "port": {"type": "integer", "default": 22}}}
+		}
+	}`)
+
+	var cases = []struct {
+		value    string
+		expected string
+	}{
+		{`{}`, `{"name": "demo", "count": 1, "options": {"verbose": false}, "tags": ["a"]}`},
+		{`null`, `{"name": "demo", "count": 1, "options": {"verbose": false}, "tags": ["a"]}`},
+		{
+			`{"name": "", "count": 0, "options": {"verbose": true}, "hosts": [{"ip": "a"}, {"ip": "b", "port": 1}], "labels": {"x": {}}, "tags": []}`,
+			`{"name": "", "count": 0, "options": {"verbose": true}, "hosts": [{"ip": "a", "port": 22}, {"ip": "b", "port": 1}], "labels": {"x": {"port": 22}}, "tags": []}`,
+		},
+		{`{"target": {"module": "m"}}`, `{"name": "demo", "count": 1, "options": {"verbose": false}, "tags": ["a"], "target": {"module": "m", "depth": 1}}`},
+	}
+
+	for _, c := range cases {
+		var value interface{}
+		require.NoError(t, json.Unmarshal([]byte(c.value), &value))
+		actual, err := json.Marshal(ApplyDefaults(root, value))
+		require.NoError(t, err)
+		assert.JSONEq(t, c.expected, string(actual), c.value)
+	}
+
+	// numbers decoded as json.Number still select the matching oneOf schema
+	numbers := mustJSON(t, `{"oneOf": [
+		{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "name": {"type": "string", "default": "demo"}}},
+		{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "kind": {"type": "string", "default": "code"}}}
+	]}`)
+	decoder := json.NewDecoder(strings.NewReader(`{"id": 9007199254740993}`))
+	decoder.UseNumber()
+	var value interface{}
+	require.NoError(t, decoder.Decode(&value))
+	actual, err := json.Marshal(ApplyDefaults(numbers, value))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": 9007199254740993, "name": "demo"}`, string(actual))
+
+	// defaults are copied instead of shared
+	first := ApplyDefaults(root, map[string]interface{}{}).(map[string]interface{})
+	first["tags"].([]interface{})[0] = "changed"
+	second := ApplyDefaults(root, map[string]interface{}{}).(map[string]interface{})
+	assert.Equal(t, []interface{}{"a"}, second["tags"])
+}
+
+func TestHasDefaults(t *testing.T) {
+	assert.True(t, HasDefaults(mustJSON(t, `{"properties": {"a": {"type": "string", "default": ""}}}`)))
+	assert.True(t, HasDefaults(mustJSON(t, `{"definitions": {"A": {"properties": {"a": {"default": 1}}}}}`)))
+	assert.True(t, HasDefaults(mustJSON(t, `{"oneOf": [{"default": 1}]}`)))
+	assert.False(t, HasDefaults(mustJSON(t, `{"properties": {"a": {"type": "string", "enum": [{"default": 1}]}}}`)))
+	assert.False(t, HasDefaults(nil))
+}
@@ -0,0 +1,306 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package schema implements the JSON schema helpers shared by framework
+// packages. It works on schemas unmarshaled to map[string]interface{}, as
+// stored in hub.PluginDetail, and supports the keywords generated by
+// github.com/alecthomas/jsonschema.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// rootPath is the path of the validated value in ValidationError.
+const rootPath = "(root)"
+
+// A ValidationError describes why a value does not match its schema.
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate validates value decoded by encoding/json against root schema.
+func Validate(root map[string]interface{}, value interface{}) error {
+	return validator{root: root}.validate(root, value, rootPath)
+}
+
+// Resolve returns the schema referenced by the $ref of s in root definitions,
+// or s itself if s is not a reference.
+func Resolve(root map[string]interface{}, s map[string]interface{}) map[string]interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := s["$ref"].(string)
+		if !ok {
+			return s
+		}
+		definitions, _ := root["definitions"].(map[string]interface{})
+		resolved, ok := definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]interface{})
+		if !ok {
+			return s
+		}
+		s = resolved
+	}
+	return s
+}
+
+type validator struct {
+	root map[string]interface{}
+}
+
+func (v validator) fail(path string, format string, args ...interface{}) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func (v validator) validate(s map[string]interface{}, value interface{}, path string) error {
+	s = Resolve(v.root, s)
+	value = numberValue(value)
+
+	if types := schemaTypes(s["type"]); len(types) > 0 && !matchAnyType(types, value) {
+		return v.fail(path, "should be %s, got %s", strings.Join(types, " or "), typeOf(value))
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		return v.fail(path, "should be one of %v", enum)
+	}
+
+	if err := v.validateCombinators(s, value, path); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return v.validateObject(s, value, path)
+	case []interface{}:
+		return v.validateArray(s, value, path)
+	case string:
+		return v.validateString(s, value, path)
+	case float64:
+		return v.validateNumber(s, value, path)
+	}
+	return nil
+}
+
+func (v validator) validateCombinators(s map[string]interface{}, value interface{}, path string) error {
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				if err := v.validate(subSchema, value, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok && v.countMatches(anyOf, value, path) == 0 {
+		return v.fail(path, "should match at least one schema of anyOf")
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok && v.countMatches(oneOf, value, path) != 1 {
+		return v.fail(path, "should match exactly one schema of oneOf")
+	}
+	return nil
+}
+
+func (v validator) countMatches(schemas []interface{}, value interface{}, path string) int {
+	matches := 0
+	for _, sub := range schemas {
+		if subSchema, ok := sub.(map[string]interface{}); ok && v.validate(subSchema, value, path) == nil {
+			matches++
+		}
+	}
+	return matches
+}
+
+func (v validator) validateObject(s map[string]interface{}, value map[string]interface{}, path string) error {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, found := value[fmt.Sprint(name)]; !found {
+				return v.fail(joinPath(path, fmt.Sprint(name)), "is required")
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	patternProperties, _ := s["patternProperties"].(map[string]interface{})
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		propertyPath := joinPath(path, key)
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			if err := v.validate(property, value[key], propertyPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		matched := false
+		for pattern, sub := range patternProperties {
+			re, err := regexp.Compile(pattern)
+			if err != nil || !re.MatchString(key) {
+				continue
+			}
+			matched = true
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				if err := v.validate(subSchema, value[key], propertyPath); err != nil {
+					return err
+				}
+			}
+		}
+		if matched {
+			continue
+		}
+
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return v.fail(propertyPath, "is not allowed")
+			}
+		case map[string]interface{}:
+			if err := v.validate(additional, value[key], propertyPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v validator) validateArray(s map[string]interface{}, value []interface{}, path string) error {
+	if minItems, ok := s["minItems"].(float64); ok && float64(len(value)) < minItems {
+		return v.fail(path, "should have at least %v items", minItems)
+	}
+	if maxItems, ok := s["maxItems"].(float64); ok && float64(len(value)) > maxItems {
+		return v.fail(path, "should have at most %v items", maxItems)
+	}
+	if items, ok := s["items"].(map[string]interface{}); ok {
+		for i, item := range value {
+			if err := v.validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v validator) validateString(s map[string]interface{}, value string, path string) error {
+	length := float64(len([]rune(value)))
+	if minLength, ok := s["minLength"].(float64); ok && length < minLength {
+		return v.fail(path, "should have at least %v characters", minLength)
+	}
+	if maxLength, ok := s["maxLength"].(float64); ok && length > maxLength {
+		return v.fail(path, "should have at most %v characters", maxLength)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return v.fail(path, "invalid pattern %s: %v", pattern, err)
+		}
+		if !re.MatchString(value) {
+			return v.fail(path, "should match pattern %s", pattern)
+		}
+	}
+	return nil
+}
+
+func (v validator) validateNumber(s map[string]interface{}, value float64, path string) error {
+	if minimum, ok := s["minimum"].(float64); ok {
+		if value < minimum || (value == minimum && s["exclusiveMinimum"] == true) {
+			return v.fail(path, "should be greater than minimum %v", minimum)
+		}
+	}
+	if maximum, ok := s["maximum"].(float64); ok {
+		if value > maximum || (value == maximum && s["exclusiveMaximum"] == true) {
+			return v.fail(path, "should be less than maximum %v", maximum)
+		}
+	}
+	return nil
+}
+
+func schemaTypes(t interface{}) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			types = append(types, fmt.Sprint(item))
+		}
+		return types
+	}
+	return nil
+}
+
+func matchAnyType(types []string, value interface{}) bool {
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// numberValue converts a json.Number decoded with json.Decoder.UseNumber
+// to float64, the only number type the validator checks.
+func numberValue(value interface{}) interface{} {
+	if n, ok := value.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path string, key string) string {
+	if path == rootPath {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,94 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustJSON(t *testing.T, data string) map[string]interface{} {
+	var v map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &v))
+	return v
+}
+
+func TestValidate(t *testing.T) {
+	root := mustJSON(t, `{
+		"type": "object",
+		"required": ["name", "host"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+			"count": {"type": "integer", "minimum": 1, "maximum": 10},
+			"mode": {"type": "string", "enum": ["fast", "safe"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"host": {"$ref": "#/definitions/Host"},
+			"labels": {"type": "object", "patternProperties": {".*": {"type": "string"}}},
+			"target": {"oneOf": [{"type": "string"}, {"type": "null"}]}
+		},
+		"definitions": {
+			"Host": {
+				"type": "object",
+				"required": ["ip"],
+				"properties": {"ip": {"type": "string"}}
+			}
+		}
+	}`)
+
+	var cases = []struct {
+		value    string
+		expected string
+	}{
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "count": 2, "mode": "fast", "tags": ["a"], "labels": {"k": "v"}, "target": null}`, ""},
+		{`{"host": {"ip": "127.0.0.1"}}`, "name: is required"},
+		{`{"name": 1, "host": {"ip": "127.0.0.1"}}`, "name: should be string, got integer"},
+		{`{"name": "", "host": {"ip": "127.0.0.1"}}`, "name: should have at least 1 characters"},
+		{`{"name": "demodemodemo", "host": {"ip": "127.0.0.1"}}`, "name: should have at most 8 characters"},
+		{`{"name": "Demo", "host": {"ip": "127.0.0.1"}}`, "name: should match pattern ^[a-z]+$"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "count": 1.5}`, "count: should be integer, got number"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "count": 11}`, "count: should be less than maximum 10"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "mode": "slow"}`, "mode: should be one of [fast safe]"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "tags": ["a", 1]}`, "tags[1]: should be string, got integer"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "tags": ["a", "b", "c"]}`, "tags: should have at most 2 items"},
+		{`{"name": "demo", "host": {}}`, "host.ip: is required"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "labels": {"k": 1}}`, "labels.k: should be string, got integer"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "target": 1}`, "target: should match exactly one schema of oneOf"},
+		{`{"name": "demo", "host": {"ip": "127.0.0.1"}, "unknown": 1}`, "unknown: is not allowed"},
+		{`[]`, "(root): should be object, got array"},
+	}
+
+	for _, c := range cases {
+		var value interface{}
+		require.NoError(t, json.Unmarshal([]byte(c.value), &value))
+		err := Validate(root, value)
+		if c.expected == "" {
+			assert.NoError(t, err, c.value)
+		} else {
+			assert.EqualError(t, err, c.expected, c.value)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	root := mustJSON(t, `{
+		"definitions": {
+			"A": {"$ref": "#/definitions/B"},
+			"B": {"type": "string"}
+		}
+	}`)
+
+	assert.Equal(t, map[string]interface{}{"type": "string"}, Resolve(root, map[string]interface{}{"$ref": "#/definitions/A"}))
+	assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/C"}, Resolve(root, map[string]interface{}{"$ref": "#/definitions/C"}))
+	assert.Equal(t, map[string]interface{}{"type": "integer"}, Resolve(root, map[string]interface{}{"type": "integer"}))
+}
@@ -0,0 +1,39 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package kit
+
+import log "github.com/sirupsen/logrus"
+
+// A Caller store the identity and request metadata of whom invokes the plugin,
+// usually parsed by runtime from the APIGW JWT and request headers.
+type Caller struct {
+	AppCode   string `json:"app_code"`
+	Operator  string `json:"operator"`
+	TenantID  string `json:"tenant_id"`
+	RequestID string `json:"request_id"`
+	Language  string `json:"language"`
+}
+
+// LogFields returns the non-empty caller fields in logrus fields format.
+func (c Caller) LogFields() log.Fields {
+	fields := log.Fields{}
+	for key, value := range map[string]string{
+		"caller_app": c.AppCode,
+		"operator":   c.Operator,
+		"tenant_id":  c.TenantID,
+		"request_id": c.RequestID,
+		"language":   c.Language,
+	} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	return fields
+}
@@ -12,10 +12,13 @@
 package kit
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/internal/schema"
 	"github.com/TencentBlueKing/bk-plugin-framework-go/runtime"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,14 +26,19 @@ import (
 // A Context store all context information and data for once plugin execution.
 type Context struct {
 	traceID          string
+	caller           Caller
 	state            constants.State
 	pollInterval     time.Duration
 	callbackTimeout  time.Duration
 	callbackPreparer func(timeout time.Duration) (runtime.CallbackPreparation, error)
 	waitingPoll      bool
 	waitingCallback  bool
+	waitErr          error
 	invokeCount      int
+	manualRetry      bool
 	reader           runtime.ContextReader
+	inputsSchema     map[string]interface{}
+	contextSchema    map[string]interface{}
 	store            runtime.ObjectStore
 	outputsStore     runtime.ObjectStore
 	*log.Entry
@@ -74,6 +82,25 @@ func (c *Context) TraceID() string {
 	return c.traceID
 }
 
+// SetCaller sets the caller of current execution and attaches the caller
+// fields to the context logger.
+func (c *Context) SetCaller(caller Caller) {
+	c.caller = caller
+	if c.Entry != nil {
+		c.Entry = c.Entry.WithFields(caller.LogFields())
+	}
+}
+
+// Caller returns the caller of current execution.
+func (c *Context) Caller() Caller {
+	return c.caller
+}
+
+// TenantID returns the tenant id of current execution's caller.
+func (c *Context) TenantID() string {
+	return c.caller.TenantID
+}
+
 // TraceID returns current state of once execution.
 func (c *Context) State() constants.State {
 	return c.state
@@ -84,6 +111,21 @@ func (c *Context) InvokeCount() int {
 	return c.invokeCount
 }
 
+// SetManualRetry marks current execution as a manual retry of a failed
+// execution and attaches the marker to the context logger.
+func (c *Context) SetManualRetry(manualRetry bool) {
+	c.manualRetry = manualRetry
+	if manualRetry && c.Entry != nil {
+		c.Entry = c.Entry.WithField("manual_retry", true)
+	}
+}
+
+// ManualRetry reports whether current execution is a manual retry of a failed
+// execution, which is resumed from its last persisted context.
+func (c *Context) ManualRetry() bool {
+	return c.manualRetry
+}
+
 // PollInterval returns next poll execute's interval.
 func (c *Context) PollInterval() time.Duration {
 	return c.pollInterval
@@ -94,10 +136,19 @@ func (c *Context) CallbackTimeout() time.Duration {
 	return c.callbackTimeout
 }
 
-// WaitPoll tells executor to execute plugin with poll state after duration.
-func (c *Context) WaitPoll(interval time.Duration) {
+// WaitPoll tells executor to execute plugin with poll state after interval.
+//
+// An execution waits for either poll or callback once, WaitPoll returns an
+// error if interval is not positive or WaitPoll or WaitCallback is already
+// called, and the error fails the execution even if Execute does not return
+// it, see WaitError.
+func (c *Context) WaitPoll(interval time.Duration) error {
+	if err := c.checkWait("WaitPoll", "interval", interval); err != nil {
+		return err
+	}
 	c.pollInterval = interval
 	c.waitingPoll = true
+	return nil
 }
 
 // WaitingPoll returns whether current execution should enter poll state.
@@ -106,9 +157,40 @@ func (c *Context) WaitingPoll() bool {
 }
 
 // WaitCallback tells executor to pause plugin execution until callback arrives.
-func (c *Context) WaitCallback(timeout time.Duration) {
+//
+// Like WaitPoll, it returns an error if timeout is not positive or WaitPoll or
+// WaitCallback is already called, and the error fails the execution.
+func (c *Context) WaitCallback(timeout time.Duration) error {
+	if err := c.checkWait("WaitCallback", "timeout", timeout); err != nil {
+		return err
+	}
 	c.callbackTimeout = timeout
 	c.waitingCallback = true
+	return nil
+}
+
+// WaitError returns the error of the first rejected WaitPoll or WaitCallback
+// call, executor fails the execution if it is not nil.
+func (c *Context) WaitError() error {
+	return c.waitErr
+}
+
+// checkWait checks the wait request of method with duration d and records
+// the first error.
+func (c *Context) checkWait(method string, name string, d time.Duration) error {
+	var err error
+	switch {
+	case d <= 0:
+		err = fmt.Errorf("%s %s must be positive, got %v", method, name, d)
+	case c.waitingPoll:
+		err = fmt.Errorf("%s is called after WaitPoll(%v), an execution can wait only once", method, c.pollInterval)
+	case c.waitingCallback:
+		err = fmt.Errorf("%s is called after WaitCallback(%v), an execution can wait only once", method, c.callbackTimeout)
+	}
+	if err != nil && c.waitErr == nil {
+		c.waitErr = err
+	}
+	return err
 }
 
 // SetCallbackPreparer sets the runtime callback preparation hook.
@@ -130,16 +212,57 @@ func (c *Context) WaitingCallback() bool {
 	return c.waitingCallback
 }
 
+// SetInputsSchemas sets the json schemas of inputs and context inputs, the
+// defaults declared by them are filled in before inputs are parsed.
+func (c *Context) SetInputsSchemas(inputs map[string]interface{}, contextInputs map[string]interface{}) {
+	c.inputsSchema = inputs
+	c.contextSchema = contextInputs
+}
+
 // ReadInputs parses inputs data and store the result
 // in the value pointed to by v.
 func (c *Context) ReadInputs(v interface{}) error {
-	return c.reader.ReadInputs(v)
+	return readWithDefaults(c.reader.ReadInputs, c.inputsSchema, v)
 }
 
 // ReadContextInputs parses context inputs data and store the result
 // in the value pointed to by v.
 func (c *Context) ReadContextInputs(v interface{}) error {
-	return c.reader.ReadContextInputs(v)
+	return readWithDefaults(c.reader.ReadContextInputs, c.contextSchema, v)
+}
+
+// readWithDefaults reads data by read, fills in the defaults declared by s
+// and stores the result in the value pointed to by v.
+func readWithDefaults(read func(v interface{}) error, s map[string]interface{}, v interface{}) error {
+	if !schema.HasDefaults(s) {
+		return read(v)
+	}
+
+	// read into an interface{} holding a *json.RawMessage, encoding/json
+	// decodes into the RawMessage so numbers can be decoded as json.Number,
+	// float64 would lose the precision of integers larger than 2^53. Readers
+	// which store the value themselves replace the RawMessage.
+	raw := &json.RawMessage{}
+	var value interface{} = raw
+	if err := read(&value); err != nil {
+		return err
+	}
+	if value == interface{}(raw) {
+		// nothing is read if the data is not sent
+		value = nil
+		if len(bytes.TrimSpace(*raw)) > 0 {
+			decoder := json.NewDecoder(bytes.NewReader(*raw))
+			decoder.UseNumber()
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+		}
+	}
+	data, err := json.Marshal(schema.ApplyDefaults(s, value))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
 }
 
 // ReadCallback parses callback data and store the result in the value pointed to by v.
@@ -153,22 +276,38 @@ func (c *Context) ReadCallback(v interface{}) error {
 
 // Write will store the value pointed to by v to context data.
 func (c *Context) Write(v interface{}) error {
-	return c.store.Write(c.traceID, v)
+	return c.writeStore(c.store, v)
 }
 
 // Read parses context data data and store the result
 // in the value pointed to by v.
 func (c *Context) Read(v interface{}) error {
-	return c.store.Read(c.traceID, v)
+	return c.readStore(c.store, v)
 }
 
 // Write will store the value pointed to by v to outputs.
 func (c *Context) WriteOutputs(v interface{}) error {
-	return c.outputsStore.Write(c.traceID, v)
+	return c.writeStore(c.outputsStore, v)
 }
 
 // ReadOutputs parses outputs data and store the result
 // in the value pointed to by v.
 func (c *Context) ReadOutputs(v interface{}) error {
-	return c.outputsStore.Read(c.traceID, v)
+	return c.readStore(c.outputsStore, v)
+}
+
+// writeStore writes v to store, isolated by tenant if the store supports it.
+func (c *Context) writeStore(store runtime.ObjectStore, v interface{}) error {
+	if tenantStore, ok := store.(runtime.TenantObjectStore); ok && c.caller.TenantID != "" {
+		return tenantStore.WriteTenant(c.caller.TenantID, c.traceID, v)
+	}
+	return store.Write(c.traceID, v)
+}
+
+// readStore reads v from store, isolated by tenant if the store supports it.
+func (c *Context) readStore(store runtime.ObjectStore, v interface{}) error {
+	if tenantStore, ok := store.(runtime.TenantObjectStore); ok && c.caller.TenantID != "" {
+		return tenantStore.ReadTenant(c.caller.TenantID, c.traceID, v)
+	}
+	return store.Read(c.traceID, v)
 }
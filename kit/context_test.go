@@ -10,6 +10,7 @@
 package kit
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -63,6 +64,20 @@ func (s *MockStore) Read(traceID string, v interface{}) error {
 	return nil
 }
 
+type MockTenantStore struct {
+	MockStore
+}
+
+func (s *MockTenantStore) WriteTenant(tenantID string, traceID string, v interface{}) error {
+	s.Called(tenantID, traceID, v)
+	return nil
+}
+
+func (s *MockTenantStore) ReadTenant(tenantID string, traceID string, v interface{}) error {
+	s.Called(tenantID, traceID, v)
+	return nil
+}
+
 func TestContext(t *testing.T) {
 	var v interface{}
 
@@ -98,13 +113,22 @@ func TestContext(t *testing.T) {
 
 	// WaitPoll test
 	assert.False(t, c.WaitingPoll())
-	c.WaitPoll(5 * time.Second)
+	assert.NoError(t, c.WaitPoll(5*time.Second))
 	assert.Equal(t, c.pollInterval, 5*time.Second)
 	assert.True(t, c.WaitingPoll())
+	assert.NoError(t, c.WaitError())
 
 	// WaitCallback test
+	c = Context{
+		traceID:      "trace",
+		state:        constants.StateEmpty,
+		invokeCount:  1,
+		reader:       &reader,
+		store:        &store,
+		outputsStore: &outputsStore,
+	}
 	assert.False(t, c.WaitingCallback())
-	c.WaitCallback(30 * time.Minute)
+	assert.NoError(t, c.WaitCallback(30*time.Minute))
 	assert.Equal(t, c.callbackTimeout, 30*time.Minute)
 	assert.True(t, c.WaitingCallback())
 
@@ -147,6 +171,32 @@ func TestContext(t *testing.T) {
 	outputsStore.AssertCalled(t, "Read", "trace", &v)
 }
 
+func TestContextWaitErrors(t *testing.T) {
+	// an execution waits for either poll or callback once, the first rejected
+	// wait call is kept in WaitError and fails the execution
+	c := Context{}
+	assert.EqualError(t, c.WaitPoll(0), "WaitPoll interval must be positive, got 0s")
+	assert.EqualError(t, c.WaitCallback(-time.Second), "WaitCallback timeout must be positive, got -1s")
+	assert.False(t, c.WaitingPoll())
+	assert.False(t, c.WaitingCallback())
+	assert.EqualError(t, c.WaitError(), "WaitPoll interval must be positive, got 0s")
+
+	c = Context{}
+	assert.NoError(t, c.WaitPoll(5*time.Second))
+	assert.EqualError(t, c.WaitCallback(time.Minute), "WaitCallback is called after WaitPoll(5s), an execution can wait only once")
+	assert.EqualError(t, c.WaitPoll(time.Second), "WaitPoll is called after WaitPoll(5s), an execution can wait only once")
+	assert.True(t, c.WaitingPoll())
+	assert.False(t, c.WaitingCallback())
+	assert.Equal(t, 5*time.Second, c.PollInterval())
+	assert.EqualError(t, c.WaitError(), "WaitCallback is called after WaitPoll(5s), an execution can wait only once")
+
+	c = Context{}
+	assert.NoError(t, c.WaitCallback(time.Minute))
+	assert.EqualError(t, c.WaitPoll(time.Second), "WaitPoll is called after WaitCallback(1m0s), an execution can wait only once")
+	assert.True(t, c.WaitingCallback())
+	assert.False(t, c.WaitingPoll())
+}
+
 func TestNewContext(t *testing.T) {
 	traceID := "trace"
 	state := constants.StateEmpty
@@ -192,3 +242,205 @@ func TestContextPrepareCallbackWithoutRuntimeSupport(t *testing.T) {
 
 	assert.EqualError(t, err, "runtime does not support callback preparation")
 }
+
+func TestContextCaller(t *testing.T) {
+	c := NewContext(
+		"trace",
+		constants.StateEmpty,
+		1,
+		&MockContextReader{},
+		&MockStore{},
+		&MockStore{},
+		log.WithFields(log.Fields{"trace_id": "trace"}),
+	)
+	assert.Equal(t, Caller{}, c.Caller())
+
+	caller := Caller{AppCode: "bk_sops", Operator: "admin", TenantID: "default", RequestID: "req-1"}
+	c.SetCaller(caller)
+
+	assert.Equal(t, caller, c.Caller())
+	assert.Equal(t, log.Fields{
+		"trace_id":   "trace",
+		"caller_app": "bk_sops",
+		"operator":   "admin",
+		"tenant_id":  "default",
+		"request_id": "req-1",
+	}, c.Entry.Data)
+}
+
+func TestContextManualRetry(t *testing.T) {
+	c := NewContext(
+		"trace",
+		constants.StatePoll,
+		3,
+		&MockContextReader{},
+		&MockStore{},
+		&MockStore{},
+		log.WithFields(log.Fields{"trace_id": "trace"}),
+	)
+	assert.False(t, c.ManualRetry())
+
+	c.SetManualRetry(true)
+
+	assert.True(t, c.ManualRetry())
+	assert.Equal(t, log.Fields{"trace_id": "trace", "manual_retry": true}, c.Entry.Data)
+}
+
+func TestContextTenantStore(t *testing.T) {
+	var v interface{}
+
+	store := MockTenantStore{}
+	store.On("Write", "trace", &v).Return(nil)
+	store.On("WriteTenant", "tenant-a", "trace", &v).Return(nil)
+	store.On("ReadTenant", "tenant-a", "trace", &v).Return(nil)
+
+	outputsStore := MockStore{}
+	outputsStore.On("Write", "trace", &v).Return(nil)
+
+	c := NewContext("trace", constants.StateEmpty, 1, &MockContextReader{}, &store, &outputsStore, log.WithFields(log.Fields{}))
+
+	// use trace keyed store without tenant
+	c.Write(&v)
+	store.AssertCalled(t, "Write", "trace", &v)
+
+	c.SetCaller(Caller{TenantID: "tenant-a"})
+	assert.Equal(t, "tenant-a", c.TenantID())
+
+	c.Write(&v)
+	store.AssertCalled(t, "WriteTenant", "tenant-a", "trace", &v)
+	c.Read(&v)
+	store.AssertCalled(t, "ReadTenant", "tenant-a", "trace", &v)
+
+	// fallback to trace keyed store if store is not tenant aware
+	c.WriteOutputs(&v)
+	outputsStore.AssertCalled(t, "Write", "trace", &v)
+}
+
+type jsonContextReader struct {
+	inputs        string
+	contextInputs string
+}
+
+func (r jsonContextReader) ReadInputs(v interface{}) error {
+	return json.Unmarshal([]byte(r.inputs), v)
+}
+
+func (r jsonContextReader) ReadContextInputs(v interface{}) error {
+	return json.Unmarshal([]byte(r.contextInputs), v)
+}
+
+func TestContextReadInputsWithDefaults(t *testing.T) {
+	type Host struct {
+		IP   string `json:"ip"`
+		Port int    `json:"port"`
+	}
+	type Inputs struct {
+		Name  string `json:"name"`
+		Hosts []Host `json:"hosts"`
+		Retry bool   `json:"retry"`
+	}
+	type ContextInputs struct {
+		Executor string `json:"executor"`
+	}
+
+	var inputsSchema, contextSchema map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "default": "demo"},
+			"hosts": {"type": "array", "items": {"$ref": "#/definitions/Host"}},
+			"retry": {"type": "boolean", "default": true}
+		},
+		"definitions": {
+			"Host": {"type": "object", "properties": {"ip": {"type": "string"}, "port": {"type": "integer", "default": 22}}}
+		}
+	}`), &inputsSchema))
+	assert.Nil(t, json.Unmarshal([]byte(`{"type": "object", "properties": {"executor": {"type": "string", "default": "admin"}}}`), &contextSchema))
+
+	reader := jsonContextReader{inputs: `{"hosts": [{"ip": "127.0.0.1"}, {"ip": "127.0.0.2", "port": 2222}], "retry": false}`, contextInputs: `null`}
+	c := NewContext("trace", constants.StateEmpty, 1, reader, nil, nil, log.WithFields(log.Fields{}))
+
+	// no schemas
+	var inputs Inputs
+	assert.Nil(t, c.ReadInputs(&inputs))
+	assert.Equal(t, Inputs{Hosts: []Host{{IP: "127.0.0.1"}, {IP: "127.0.0.2", Port: 2222}}}, inputs)
+
+	c.SetInputsSchemas(inputsSchema, contextSchema)
+	inputs = Inputs{}
+	assert.Nil(t, c.ReadInputs(&inputs))
+	assert.Equal(t, Inputs{Name: "demo", Hosts: []Host{{IP: "127.0.0.1", Port: 22}, {IP: "127.0.0.2", Port: 2222}}}, inputs)
+
+	var contextInputs ContextInputs
+	assert.Nil(t, c.ReadContextInputs(&contextInputs))
+	assert.Equal(t, ContextInputs{Executor: "admin"}, contextInputs)
+}
+
+func TestContextReadInputsWithDefaultsKeepsIntegerPrecision(t *testing.T) {
+	type Inputs struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var inputsSchema map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"name": {"type": "string", "default": "demo"}
+		}
+	}`), &inputsSchema))
+
+	reader := jsonContextReader{inputs: `{"id": 9007199254740993}`, contextInputs: `null`}
+	c := NewContext("trace", constants.StateEmpty, 1, reader, nil, nil, log.WithFields(log.Fields{}))
+	c.SetInputsSchemas(inputsSchema, nil)
+
+	var inputs Inputs
+	assert.Nil(t, c.ReadInputs(&inputs))
+	assert.Equal(t, Inputs{ID: 9007199254740993, Name: "demo"}, inputs)
+}
+
+// valueContextReader stores the inputs without encoding/json, or nothing if
+// the inputs are nil.
+type valueContextReader struct {
+	inputs interface{}
+}
+
+func (r valueContextReader) ReadInputs(v interface{}) error {
+	if r.inputs != nil {
+		*v.(*interface{}) = r.inputs
+	}
+	return nil
+}
+
+func (r valueContextReader) ReadContextInputs(v interface{}) error {
+	return nil
+}
+
+func TestContextReadInputsWithDefaultsFromReaders(t *testing.T) {
+	type Inputs struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var inputsSchema map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"type": "object", "properties": {"name": {"type": "string", "default": "demo"}}}`), &inputsSchema))
+
+	var cases = []struct {
+		reader   runtime.ContextReader
+		expected Inputs
+	}{
+		// inputs are not sent
+		{valueContextReader{}, Inputs{Name: "demo"}},
+		{jsonContextReader{inputs: `null`}, Inputs{Name: "demo"}},
+		// inputs are stored without encoding/json
+		{valueContextReader{inputs: map[string]interface{}{"id": float64(1)}}, Inputs{ID: 1, Name: "demo"}},
+	}
+	for _, c := range cases {
+		ctx := NewContext("trace", constants.StateEmpty, 1, c.reader, nil, nil, log.WithFields(log.Fields{}))
+		ctx.SetInputsSchemas(inputsSchema, nil)
+
+		var inputs Inputs
+		assert.Nil(t, ctx.ReadInputs(&inputs))
+		assert.Equal(t, c.expected, inputs)
+	}
+}
@@ -0,0 +1,35 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuration(t *testing.T) {
+	var v struct {
+		Timeout  Duration `json:"timeout"`
+		Interval Duration `json:"interval"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"timeout": "1m30s", "interval": 1000000000}`), &v))
+	assert.Equal(t, 90*time.Second, v.Timeout.Duration())
+	assert.Equal(t, time.Second, v.Interval.Duration())
+
+	data, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"timeout": "1m30s", "interval": "1s"}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"timeout": "soon"}`), &v))
+	assert.EqualError(t, json.Unmarshal([]byte(`{"timeout": true}`), &v), "invalid duration true")
+}
@@ -9,8 +9,120 @@
 
 package kit
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
 // Form is alias for map[string]map[string]interface{}
 type Form map[string]map[string]interface{}
 
 // F is alias for map[string]interface{}
 type F map[string]interface{}
+
+// These are the names of renderform components rendered by the frontend.
+const (
+	ComponentInput       = "bk-input"
+	ComponentInputNumber = "bk-input-number"
+	ComponentSelect      = "bk-select"
+	ComponentSwitcher    = "bk-switcher"
+)
+
+// RemoteOptionsKey is the form field attribute storing the plugin API which
+// provides the options of the field, it is rendered to renderform remote
+// config by protocol.BuildDetail.
+const RemoteOptionsKey = "ui:remote"
+
+// RemoteOptionsSource describes the plugin API which provides the options of
+// a form field.
+//
+// Path is the path the API registered by pluginapi, relative to the version
+// prefix for version-scoped APIs. The API should respond options in the
+// standard envelope, whose data is a list of objects carrying LabelKey and
+// ValueKey.
+type RemoteOptionsSource struct {
+	Path     string                 `json:"path"`
+	Method   string                 `json:"method"`
+	LabelKey string                 `json:"label_key"`
+	ValueKey string                 `json:"value_key"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+}
+
+// RemoteOption customizes the source returned by RemoteOptions.
+type RemoteOption func(*RemoteOptionsSource)
+
+// WithRemoteMethod sets the method of the options API, GET by default.
+func WithRemoteMethod(method string) RemoteOption {
+	return func(s *RemoteOptionsSource) {
+		s.Method = strings.ToUpper(method)
+	}
+}
+
+// WithRemoteKeys sets the keys of option label and value in the API response
+// data, "label" and "value" by default.
+func WithRemoteKeys(labelKey string, valueKey string) RemoteOption {
+	return func(s *RemoteOptionsSource) {
+		s.LabelKey = labelKey
+		s.ValueKey = valueKey
+	}
+}
+
+// WithRemoteParams sets the query params sent to the options API.
+func WithRemoteParams(params map[string]interface{}) RemoteOption {
+	return func(s *RemoteOptionsSource) {
+		s.Params = params
+	}
+}
+
+// RemoteOptions returns form field attributes binding the options of the field
+// to the plugin API registered on path, e.g.
+//
+//	kit.Form{"host": kit.RemoteOptions("/hosts", kit.WithRemoteKeys("ip", "id"))}
+//
+// More attributes can be added to the returned F.
+func RemoteOptions(path string, opts ...RemoteOption) F {
+	source := RemoteOptionsSource{
+		Path:     path,
+		Method:   http.MethodGet,
+		LabelKey: "label",
+		ValueKey: "value",
+	}
+	for _, opt := range opts {
+		opt(&source)
+	}
+	return F{RemoteOptionsKey: source}
+}
+
+// RemoteOptionsOf returns the remote options source in form field attributes,
+// the bool result reports whether attrs has one.
+func RemoteOptionsOf(attrs map[string]interface{}) (RemoteOptionsSource, bool, error) {
+	value, found := attrs[RemoteOptionsKey]
+	if !found {
+		return RemoteOptionsSource{}, false, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return RemoteOptionsSource{}, true, err
+	}
+	var source RemoteOptionsSource
+	if err := json.Unmarshal(raw, &source); err != nil {
+		return RemoteOptionsSource{}, true, fmt.Errorf("invalid %s: %v", RemoteOptionsKey, err)
+	}
+	if !strings.HasPrefix(source.Path, "/") {
+		return RemoteOptionsSource{}, true, fmt.Errorf("%s path %q should start with /", RemoteOptionsKey, source.Path)
+	}
+	if source.Method == "" {
+		source.Method = http.MethodGet
+	}
+	source.Method = strings.ToUpper(source.Method)
+	if source.LabelKey == "" {
+		source.LabelKey = "label"
+	}
+	if source.ValueKey == "" {
+		source.ValueKey = "value"
+	}
+	return source, true, nil
+}
@@ -0,0 +1,89 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteOptions(t *testing.T) {
+	attrs := RemoteOptions("/hosts",
+		WithRemoteMethod("post"),
+		WithRemoteKeys("ip", "id"),
+		WithRemoteParams(map[string]interface{}{"bk_biz_id": "2"}),
+	)
+	attrs["title"] = "Host"
+
+	source, found, err := RemoteOptionsOf(attrs)
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, RemoteOptionsSource{
+		Path:     "/hosts",
+		Method:   "POST",
+		LabelKey: "ip",
+		ValueKey: "id",
+		Params:   map[string]interface{}{"bk_biz_id": "2"},
+	}, source)
+
+	// decode from render form json with defaults
+	var form map[string]map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"host": {"ui:remote": {"path": "/hosts"}}, "name": {"title": "Name"}}`), &form))
+	source, found, err = RemoteOptionsOf(form["host"])
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, RemoteOptionsSource{Path: "/hosts", Method: "GET", LabelKey: "label", ValueKey: "value"}, source)
+
+	_, found, err = RemoteOptionsOf(form["name"])
+	assert.Nil(t, err)
+	assert.False(t, found)
+
+	_, found, err = RemoteOptionsOf(F{RemoteOptionsKey: "hosts"})
+	assert.True(t, found)
+	assert.EqualError(t, err, "invalid ui:remote: json: cannot unmarshal string into Go value of type kit.RemoteOptionsSource")
+}
+
+func TestFormBuilder(t *testing.T) {
+	form := NewForm().
+		Field("template_id").Title("Template").Select(Option{Label: "A", Value: 1}).
+		Field("desc").Input().Textarea().Props(F{"rows": 3}).
+		Field("host").Remote("/hosts").Reaction(Reaction{
+		Lifetime: "watch",
+		Source:   "template_id",
+		If:       "{{ $self.getValue('template_id') === 1 }}",
+		Then:     F{"state": F{"visible": true}},
+	}).
+		Field("template_id").Description("Template to run").
+		Form()
+
+	assert.Equal(t, Form{
+		"template_id": {
+			"title":        "Template",
+			"description":  "Template to run",
+			"ui:component": map[string]interface{}{"name": "bk-select", "props": map[string]interface{}{"datasource": []Option{{Label: "A", Value: 1}}}},
+		},
+		"desc": {
+			"ui:component": map[string]interface{}{"name": "bk-input", "props": map[string]interface{}{"type": "textarea", "rows": 3}},
+		},
+		"host": {
+			RemoteOptionsKey: RemoteOptionsSource{Path: "/hosts", Method: "GET", LabelKey: "label", ValueKey: "value"},
+			"ui:reactions": []Reaction{{
+				Lifetime: "watch",
+				Source:   "template_id",
+				If:       "{{ $self.getValue('template_id') === 1 }}",
+				Then:     F{"state": F{"visible": true}},
+			}},
+		},
+	}, form)
+
+	assert.JSONEq(t, `{"name": {"ui:component": {"name": "bk-select", "props": {"datasource": []}}}}`, string(NewForm().Field("name").Select().JSON()))
+}
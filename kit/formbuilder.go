@@ -0,0 +1,170 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package kit
+
+import "encoding/json"
+
+// Option is a select option of form field.
+type Option struct {
+	Label string      `json:"label"`
+	Value interface{} `json:"value"`
+}
+
+// Reaction is a renderform reaction of form field, which changes the field
+// state when the condition on source field is satisfied.
+type Reaction struct {
+	Lifetime string `json:"lifetime,omitempty"`
+	Source   string `json:"source,omitempty"`
+	If       string `json:"if,omitempty"`
+	Then     F      `json:"then,omitempty"`
+}
+
+// FormBuilder builds Form with typed field attribute setters, e.g.
+//
+//	kit.NewForm().
+//		Field("template_id").Title("Template").Select(kit.Option{Label: "A", Value: 1}).
+//		Field("desc").Input().Textarea().
+//		JSON()
+type FormBuilder struct {
+	form Form
+}
+
+// NewForm returns an empty FormBuilder.
+func NewForm() *FormBuilder {
+	return &FormBuilder{form: Form{}}
+}
+
+// Field returns the builder of field name, the field is created if absent.
+func (b *FormBuilder) Field(name string) *FieldBuilder {
+	attrs, found := b.form[name]
+	if !found {
+		attrs = map[string]interface{}{}
+		b.form[name] = attrs
+	}
+	return &FieldBuilder{form: b, attrs: attrs}
+}
+
+// Form returns the built Form.
+func (b *FormBuilder) Form() Form {
+	return b.form
+}
+
+// JSON returns the built Form in JSON format, which can be used as
+// hub.PluginSpec Form. It panics if the form can not be marshaled.
+func (b *FormBuilder) JSON() []byte {
+	data, err := json.Marshal(b.form)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// FieldBuilder sets attributes of a form field.
+type FieldBuilder struct {
+	form  *FormBuilder
+	attrs map[string]interface{}
+}
+
+// Field returns the builder of another field of the form.
+func (f *FieldBuilder) Field(name string) *FieldBuilder {
+	return f.form.Field(name)
+}
+
+// Form returns the built Form.
+func (f *FieldBuilder) Form() Form {
+	return f.form.Form()
+}
+
+// JSON returns the built Form in JSON format.
+func (f *FieldBuilder) JSON() []byte {
+	return f.form.JSON()
+}
+
+// Set sets attribute key of the field to value.
+func (f *FieldBuilder) Set(key string, value interface{}) *FieldBuilder {
+	f.attrs[key] = value
+	return f
+}
+
+// Title sets the title of the field.
+func (f *FieldBuilder) Title(title string) *FieldBuilder {
+	return f.Set("title", title)
+}
+
+// Description sets the description of the field.
+func (f *FieldBuilder) Description(description string) *FieldBuilder {
+	return f.Set("description", description)
+}
+
+// Component sets the renderform component of the field, props are merged
+// into the props of the component.
+func (f *FieldBuilder) Component(name string, props F) *FieldBuilder {
+	component := f.component()
+	component["name"] = name
+	return f.Props(props)
+}
+
+// Props merges props into the props of the field component.
+func (f *FieldBuilder) Props(props F) *FieldBuilder {
+	if len(props) == 0 {
+		return f
+	}
+	component := f.component()
+	merged, _ := component["props"].(map[string]interface{})
+	if merged == nil {
+		merged = map[string]interface{}{}
+		component["props"] = merged
+	}
+	for k, v := range props {
+		merged[k] = v
+	}
+	return f
+}
+
+// Input sets the field component to ComponentInput.
+func (f *FieldBuilder) Input() *FieldBuilder {
+	return f.Component(ComponentInput, nil)
+}
+
+// Textarea sets the field component to ComponentInput with textarea type.
+func (f *FieldBuilder) Textarea() *FieldBuilder {
+	return f.Component(ComponentInput, F{"type": "textarea"})
+}
+
+// Select sets the field component to ComponentSelect with static options.
+func (f *FieldBuilder) Select(options ...Option) *FieldBuilder {
+	if options == nil {
+		options = []Option{}
+	}
+	return f.Component(ComponentSelect, F{"datasource": options})
+}
+
+// Remote binds the options of the field to a plugin API, see RemoteOptions.
+func (f *FieldBuilder) Remote(path string, opts ...RemoteOption) *FieldBuilder {
+	for k, v := range RemoteOptions(path, opts...) {
+		f.attrs[k] = v
+	}
+	return f
+}
+
+// Reaction appends a renderform reaction to the field.
+func (f *FieldBuilder) Reaction(reaction Reaction) *FieldBuilder {
+	reactions, _ := f.attrs["ui:reactions"].([]Reaction)
+	return f.Set("ui:reactions", append(reactions, reaction))
+}
+
+func (f *FieldBuilder) component() map[string]interface{} {
+	component, _ := f.attrs["ui:component"].(map[string]interface{})
+	if component == nil {
+		component = map[string]interface{}{}
+		f.attrs["ui:component"] = component
+	}
+	return component
+}
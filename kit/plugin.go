@@ -21,3 +21,15 @@ type Plugin interface {
 	Desc() string
 	Execute(*Context) error
 }
+
+// Canceler is an optional interface implemented by plugins which need to
+// clean up when an execution is cancelled, e.g. abort the external jobs they
+// started.
+//
+// Cancel is called instead of Execute on the next invocation after the
+// execution is cancelled while waiting for poll or callback, c.State()
+// returns the waiting state. The execution fails if Cancel returns an error,
+// and is marked as constants.StateCancelled otherwise.
+type Canceler interface {
+	Cancel(c *Context) error
+}
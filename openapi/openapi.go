@@ -0,0 +1,385 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package openapi generates the OpenAPI 3 document of a plugin, covering
+// the standard /bk_plugin/* endpoints of every installed version and the
+// custom plugin APIs registered by pluginapi.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+// DefaultPluginAPIPrefix is the path prefix under which runtime serves plugin APIs.
+const DefaultPluginAPIPrefix = protocol.DefaultPluginAPIPrefix
+
+// Version is the OpenAPI specification version of generated documents.
+const Version = "3.0.3"
+
+const contentTypeJSON = "application/json"
+
+// Options stores the options used to generate documents.
+type Options struct {
+	Title           string
+	Description     string
+	Version         string
+	PluginAPIPrefix string
+}
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info is the info object of an OpenAPI document.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem stores the operations of one path by lower case HTTP method.
+type PathItem map[string]*Operation
+
+// Operation is the operation object of an OpenAPI document.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is the parameter object of an OpenAPI document.
+type Parameter struct {
+	Name     string                 `json:"name"`
+	In       string                 `json:"in"`
+	Required bool                   `json:"required"`
+	Schema   map[string]interface{} `json:"schema"`
+}
+
+// RequestBody is the request body object of an OpenAPI document.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is the response object of an OpenAPI document.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the media type object of an OpenAPI document.
+type MediaType struct {
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// Components stores the reusable schemas of an OpenAPI document.
+type Components struct {
+	Schemas map[string]interface{} `json:"schemas"`
+}
+
+// Generate returns the OpenAPI document of installed plugin versions and
+// registered plugin APIs.
+func Generate(opts Options) (*Document, error) {
+	if opts.PluginAPIPrefix == "" {
+		opts.PluginAPIPrefix = DefaultPluginAPIPrefix
+	}
+	if opts.Version == "" {
+		if versions := hub.GetPluginVersions(); len(versions) > 0 {
+			opts.Version = versions[0]
+		}
+	}
+
+	g := &generator{doc: &Document{
+		OpenAPI:    Version,
+		Info:       Info{Title: opts.Title, Description: opts.Description, Version: opts.Version},
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]interface{}{}},
+	}}
+
+	if err := g.addStandardOperations(); err != nil {
+		return nil, err
+	}
+	for _, route := range pluginapi.Routes() {
+		if err := g.addPluginAPIOperation(opts.PluginAPIPrefix, route); err != nil {
+			return nil, err
+		}
+	}
+	return g.doc, nil
+}
+
+type generator struct {
+	doc *Document
+}
+
+func (g *generator) addOperation(method string, path string, op *Operation) {
+	item, found := g.doc.Paths[path]
+	if !found {
+		item = PathItem{}
+		g.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+func (g *generator) addStandardOperations() error {
+	meta, err := g.reflect(protocol.MetaData{})
+	if err != nil {
+		return err
+	}
+	g.addOperation(http.MethodGet, "/bk_plugin/meta", &Operation{
+		OperationID: "meta",
+		Summary:     "Get plugin meta",
+		Tags:        []string{"bk_plugin"},
+		Responses:   jsonResponses(meta),
+	})
+
+	g.addOperation(http.MethodGet, "/bk_plugin/schedule/{trace_id}", &Operation{
+		OperationID: "schedule",
+		Summary:     "Get plugin execution state",
+		Tags:        []string{"bk_plugin"},
+		Parameters:  []Parameter{pathParameter("trace_id")},
+		Responses:   jsonResponses(executionSchema(map[string]interface{}{"type": "object"})),
+	})
+
+	detail, err := g.reflect(protocol.DetailData{})
+	if err != nil {
+		return err
+	}
+	for _, version := range hub.GetPluginVersions() {
+		pluginDetail, err := hub.GetPluginDetail(version)
+		if err != nil {
+			return err
+		}
+		id := operationID(version)
+
+		g.addOperation(http.MethodGet, "/bk_plugin/detail/"+version, &Operation{
+			OperationID: "detail_" + id,
+			Summary:     fmt.Sprintf("Get plugin %s detail", version),
+			Description: pluginDetail.Plugin().Desc(),
+			Tags:        []string{"bk_plugin"},
+			Responses:   jsonResponses(detail),
+		})
+
+		inputs := g.addSchema("v"+version+".Inputs", pluginDetail.InputsSchemaJSON())
+		contextInputs := g.addSchema("v"+version+".ContextInputs", pluginDetail.ContextInputsSchemaJSON())
+		outputs := g.addSchema("v"+version+".Outputs", pluginDetail.OutputsSchemaJSON())
+		g.addOperation(http.MethodPost, "/bk_plugin/invoke/"+version, &Operation{
+			OperationID: "invoke_" + id,
+			Summary:     fmt.Sprintf("Invoke plugin %s", version),
+			Description: pluginDetail.Plugin().Desc(),
+			Tags:        []string{"bk_plugin"},
+			RequestBody: jsonRequestBody(map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"inputs"},
+				"properties": map[string]interface{}{
+					"inputs":  inputs,
+					"context": contextInputs,
+				},
+			}),
+			Responses: jsonResponses(executionSchema(outputs)),
+		})
+	}
+	return nil
+}
+
+func (g *generator) addPluginAPIOperation(prefix string, route pluginapi.Route) error {
+	path, params := convertPath(prefix + route.Path)
+
+	op := &Operation{
+		OperationID: "plugin_api_" + operationID(route.Method+"_"+route.Path),
+		Summary:     route.Operation.Summary,
+		Description: route.Operation.Description,
+		Tags:        route.Operation.Tags,
+		Responses:   jsonResponses(map[string]interface{}{}),
+	}
+	if len(op.Tags) == 0 {
+		op.Tags = []string{"plugin_api"}
+	}
+	for _, param := range params {
+		op.Parameters = append(op.Parameters, pathParameter(param))
+	}
+
+	if route.Operation.Request != nil {
+		request, err := g.reflect(route.Operation.Request)
+		if err != nil {
+			return err
+		}
+		op.RequestBody = jsonRequestBody(request)
+	}
+	if route.Operation.Response != nil {
+		response, err := g.reflect(route.Operation.Response)
+		if err != nil {
+			return err
+		}
+		op.Responses = jsonResponses(response)
+	}
+
+	g.addOperation(route.Method, path, op)
+	return nil
+}
+
+// reflect returns the schema of v, with its definitions moved to components.
+func (g *generator) reflect(v interface{}) (map[string]interface{}, error) {
+	s, err := hub.ReflectSchema(v)
+	if err != nil {
+		return nil, err
+	}
+	return g.moveDefinitions(s), nil
+}
+
+// addSchema stores s in components with name and returns a reference to it.
+func (g *generator) addSchema(name string, s map[string]interface{}) map[string]interface{} {
+	g.doc.Components.Schemas[name] = g.moveDefinitions(s)
+	return schemaRef(name)
+}
+
+// moveDefinitions returns a copy of s without $schema and definitions, whose
+// definitions are stored in components and references are rewritten.
+func (g *generator) moveDefinitions(s map[string]interface{}) map[string]interface{} {
+	definitions, _ := s["definitions"].(map[string]interface{})
+
+	renames := map[string]string{}
+	for name, definition := range definitions {
+		renames[name] = g.uniqueSchemaName(name, definition)
+	}
+	for name, definition := range definitions {
+		g.doc.Components.Schemas[renames[name]] = rewriteRefs(definition, renames)
+	}
+
+	copied := map[string]interface{}{}
+	for k, v := range s {
+		if k == "definitions" {
+			continue
+		}
+		copied[k] = rewriteRefs(v, renames)
+	}
+	return copied
+}
+
+func (g *generator) uniqueSchemaName(name string, definition interface{}) string {
+	candidate := name
+	for i := 2; ; i++ {
+		existing, found := g.doc.Components.Schemas[candidate]
+		if !found || reflect.DeepEqual(existing, rewriteRefs(definition, nil)) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// rewriteRefs returns a copy of v whose definition references point to
+// components, nested $schema keywords are dropped as OpenAPI does not allow them.
+func rewriteRefs(v interface{}, renames map[string]string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if key == "$schema" {
+				continue
+			}
+			if ref, ok := value.(string); key == "$ref" && ok {
+				name := strings.TrimPrefix(ref, "#/definitions/")
+				if renamed, found := renames[name]; found {
+					name = renamed
+				}
+				copied[key] = "#/components/schemas/" + name
+				continue
+			}
+			copied[key] = rewriteRefs(value, renames)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = rewriteRefs(item, renames)
+		}
+		return copied
+	}
+	return v
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func executionSchema(outputs map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"trace_id": map[string]interface{}{"type": "string"},
+			"state":    map[string]interface{}{"type": "integer"},
+			"outputs":  outputs,
+			"err":      map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+func jsonRequestBody(s map[string]interface{}) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{contentTypeJSON: {Schema: s}},
+	}
+}
+
+// jsonResponses returns responses whose data is wrapped in protocol.Response.
+func jsonResponses(data map[string]interface{}) map[string]Response {
+	return map[string]Response{
+		"200": {
+			Description: "success",
+			Content: map[string]MediaType{contentTypeJSON: {Schema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"result":  map[string]interface{}{"type": "boolean"},
+					"code":    map[string]interface{}{"type": "integer"},
+					"message": map[string]interface{}{"type": "string"},
+					"data":    data,
+				},
+			}}},
+		},
+	}
+}
+
+func pathParameter(name string) Parameter {
+	return Parameter{Name: name, In: "path", Required: true, Schema: map[string]interface{}{"type": "string"}}
+}
+
+// convertPath converts :param and *wildcard segments to OpenAPI {param}
+// templates, and returns the param names.
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+var operationIDRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+func operationID(s string) string {
+	return strings.Trim(operationIDRe.ReplaceAllString(strings.ToLower(s), "_"), "_")
+}
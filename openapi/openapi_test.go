@@ -0,0 +1,140 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+)
+
+type openapiTestPlugin struct{}
+
+func (p openapiTestPlugin) Version() string            { return "13.0.0" }
+func (p openapiTestPlugin) Desc() string               { return "openapi plugin" }
+func (p openapiTestPlugin) Execute(*kit.Context) error { return nil }
+
+type Host struct {
+	IP string `json:"ip"`
+}
+
+type openapiTestInputs struct {
+	Hosts []Host `json:"hosts"`
+}
+
+type openapiTestOutputs struct {
+	Count int `json:"count"`
+}
+
+type listTasksResponse struct {
+	Tasks []Host `json:"tasks"`
+}
+
+type createTaskRequest struct {
+	Name string `json:"name"`
+}
+
+func TestGenerate(t *testing.T) {
+	hub.MustInstallV2(openapiTestPlugin{}, hub.PluginSpec{
+		Inputs:  openapiTestInputs{},
+		Outputs: openapiTestOutputs{},
+	})
+	pluginapi.ResetForTest()
+	t.Cleanup(pluginapi.ResetForTest)
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	pluginapi.Register(func(r pluginapi.Router) {
+		r.Group("/tasks", func(r pluginapi.Router) {
+			r.GET("/:id", noop)
+			pluginapi.Describe(r, http.MethodGet, "/:id", pluginapi.Operation{
+				Summary:  "Get task",
+				Response: listTasksResponse{},
+			})
+			r.POST("", noop)
+			pluginapi.Describe(r, http.MethodPost, "", pluginapi.Operation{
+				Summary: "Create task",
+				Tags:    []string{"task"},
+				Request: createTaskRequest{},
+			})
+		})
+		r.GET("/files/*path", noop)
+	})
+
+	doc, err := Generate(Options{Title: "demo plugin"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, Info{Title: "demo plugin", Version: "13.0.0"}, doc.Info)
+
+	// standard endpoints
+	assert.Contains(t, doc.Paths, "/bk_plugin/meta")
+	assert.Contains(t, doc.Paths, "/bk_plugin/schedule/{trace_id}")
+	assert.Equal(t, "detail_13_0_0", doc.Paths["/bk_plugin/detail/13.0.0"]["get"].OperationID)
+	invoke := doc.Paths["/bk_plugin/invoke/13.0.0"]["post"]
+	require.NotNil(t, invoke)
+	assert.Equal(t, "invoke_13_0_0", invoke.OperationID)
+	assert.Equal(t, "openapi plugin", invoke.Description)
+	assert.Equal(t,
+		map[string]interface{}{"$ref": "#/components/schemas/v13.0.0.Inputs"},
+		invoke.RequestBody.Content["application/json"].Schema["properties"].(map[string]interface{})["inputs"],
+	)
+	assert.Contains(t, doc.Components.Schemas, "v13.0.0.Inputs")
+	assert.Contains(t, doc.Components.Schemas, "v13.0.0.Outputs")
+	assert.Contains(t, doc.Components.Schemas, "Host")
+
+	// plugin apis
+	getTask := doc.Paths["/bk_plugin/plugin_api/tasks/{id}"]["get"]
+	require.NotNil(t, getTask)
+	assert.Equal(t, "plugin_api_get_tasks_id", getTask.OperationID)
+	assert.Equal(t, "Get task", getTask.Summary)
+	assert.Equal(t, []string{"plugin_api"}, getTask.Tags)
+	assert.Equal(t, []Parameter{{Name: "id", In: "path", Required: true, Schema: map[string]interface{}{"type": "string"}}}, getTask.Parameters)
+	raw, err := json.Marshal(getTask.Responses["200"])
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"items":{"$ref":"#/components/schemas/Host"}`)
+
+	createTask := doc.Paths["/bk_plugin/plugin_api/tasks"]["post"]
+	require.NotNil(t, createTask)
+	assert.Equal(t, []string{"task"}, createTask.Tags)
+	assert.Contains(t, createTask.RequestBody.Content["application/json"].Schema["properties"], "name")
+
+	files := doc.Paths["/bk_plugin/plugin_api/files/{path}"]["get"]
+	require.NotNil(t, files)
+	assert.Equal(t, "path", files.Parameters[0].Name)
+	assert.Nil(t, files.RequestBody)
+
+	_, err = json.Marshal(doc)
+	assert.NoError(t, err)
+}
+
+func TestConvertPath(t *testing.T) {
+	var cases = []struct {
+		in             string
+		expectedPath   string
+		expectedParams []string
+	}{
+		{"/tasks", "/tasks", nil},
+		{"/tasks/:id/logs/:log_id", "/tasks/{id}/logs/{log_id}", []string{"id", "log_id"}},
+		{"/files/*path", "/files/{path}", []string{"path"}},
+	}
+
+	for _, c := range cases {
+		path, params := convertPath(c.in)
+		assert.Equal(t, c.expectedPath, path)
+		assert.Equal(t, c.expectedParams, params)
+	}
+}
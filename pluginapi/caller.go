@@ -0,0 +1,52 @@
+package pluginapi
+
+import (
+	"context"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+type callerContextKey struct{}
+
+type loggerContextKey struct{}
+
+// WithCaller returns a request carrying the caller identity and request
+// metadata supplied by the runtime.
+func WithCaller(r *http.Request, caller kit.Caller) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), callerContextKey{}, caller))
+}
+
+// CallerFrom returns the caller attached by the runtime, the bool result
+// reports whether a caller is attached.
+func CallerFrom(r *http.Request) (kit.Caller, bool) {
+	caller, ok := r.Context().Value(callerContextKey{}).(kit.Caller)
+	return caller, ok
+}
+
+// WithLogger returns a request carrying logger as the base of Logger.
+func WithLogger(r *http.Request, logger *log.Entry) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), loggerContextKey{}, logger))
+}
+
+// Logger returns a logger scoped to the request, which carries the method,
+// path and the caller fields of the request.
+//
+// The logger attached by WithLogger is used as base if present, otherwise the
+// logrus standard logger is used.
+func Logger(r *http.Request) *log.Entry {
+	logger, ok := r.Context().Value(loggerContextKey{}).(*log.Entry)
+	if !ok || logger == nil {
+		logger = log.NewEntry(log.StandardLogger())
+	}
+	logger = logger.WithFields(log.Fields{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	})
+	if caller, ok := CallerFrom(r); ok {
+		logger = logger.WithFields(caller.LogFields())
+	}
+	return logger
+}
@@ -0,0 +1,36 @@
+package pluginapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+func TestCallerFromAndLogger(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/tasks/42", nil)
+	_, ok := CallerFrom(req)
+	assert.False(t, ok)
+	assert.Equal(t, log.Fields{"method": "GET", "path": "/tasks/42"}, Logger(req).Data)
+
+	caller := kit.Caller{AppCode: "bk_sops", Operator: "admin", TenantID: "tenant-a", RequestID: "req-1"}
+	req = WithCaller(req, caller)
+	got, ok := CallerFrom(req)
+	assert.True(t, ok)
+	assert.Equal(t, caller, got)
+
+	req = WithLogger(req, log.WithField("trace_id", "trace-1"))
+	assert.Equal(t, log.Fields{
+		"trace_id":   "trace-1",
+		"method":     "GET",
+		"path":       "/tasks/42",
+		"caller_app": "bk_sops",
+		"operator":   "admin",
+		"tenant_id":  "tenant-a",
+		"request_id": "req-1",
+	}, Logger(req).Data)
+}
@@ -0,0 +1,230 @@
+// Package httpmux serves plugin APIs registered by pluginapi with net/http,
+// so they can be served and tested without the runtime.
+package httpmux
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+const (
+	// CodeNotFound is the response code returned when no route matches the request path.
+	CodeNotFound = 40400
+	// CodeMethodNotAllowed is the response code returned when routes match the
+	// request path but none of them matches the request method.
+	CodeMethodNotAllowed = 40500
+)
+
+const (
+	segmentStatic = iota
+	segmentParam
+	segmentWildcard
+)
+
+type segment struct {
+	kind  int
+	value string
+}
+
+type route struct {
+	pluginapi.Route
+	segments []segment
+}
+
+// Mux is an http.Handler dispatching requests to plugin API routes.
+//
+// Path patterns support ":name" segments which match a single path segment
+// and a trailing "*name" segment which matches the rest of the path, matched
+// values are attached to the request by pluginapi.WithParams. Static segments
+// take precedence over params, and params over wildcards.
+type Mux struct {
+	routes []route
+}
+
+// Default returns a Mux serving the routes registered by pluginapi.Register.
+//
+// It panics if pluginapi.CheckRemoteOptions fails, so form remote options
+// referring to unregistered plugin APIs are found when runtime starts rather
+// than when the detail API is requested.
+func Default() *Mux {
+	if err := pluginapi.CheckRemoteOptions(); err != nil {
+		panic(err)
+	}
+	return New(pluginapi.Registrars()...)
+}
+
+// New returns a Mux serving the routes registered by registrars. It panics if
+// a route has a wildcard segment which is not the last segment.
+func New(registrars ...pluginapi.Registrar) *Mux {
+	m := &Mux{}
+	for _, r := range pluginapi.CollectRoutes(registrars) {
+		segments, err := parsePattern(r.Path)
+		if err != nil {
+			panic(fmt.Errorf("invalid plugin api route %s %s: %v", r.Method, r.Path, err))
+		}
+		m.routes = append(m.routes, route{Route: r, segments: segments})
+	}
+	return m
+}
+
+// Routes returns the routes served by m.
+func (m *Mux) Routes() []pluginapi.Route {
+	routes := make([]pluginapi.Route, 0, len(m.routes))
+	for _, r := range m.routes {
+		routes = append(routes, r.Route)
+	}
+	return routes
+}
+
+// Methods returns the sorted methods allowed on path, HEAD is allowed if GET
+// is allowed and OPTIONS is allowed if any method is allowed.
+func (m *Mux) Methods(path string) []string {
+	set := map[string]bool{}
+	for _, r := range m.routes {
+		if _, ok := match(r.segments, path); ok {
+			set[r.Method] = true
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	if set[http.MethodGet] {
+		set[http.MethodHead] = true
+	}
+	set[http.MethodOptions] = true
+
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// ServeHTTP dispatches the request to the most specific route matches its
+// method and path. Requests match no route get a 404 response, and requests
+// match routes of other methods get a 405 response with Allow header, both
+// in the standard envelope. OPTIONS requests are answered with Allow header
+// unless routed explicitly.
+func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, params, found := m.lookup(r.Method, r.URL.Path)
+	if !found && r.Method == http.MethodHead {
+		rt, params, found = m.lookup(http.MethodGet, r.URL.Path)
+	}
+	if found {
+		rt.Handler.ServeHTTP(w, pluginapi.WithParams(r, params))
+		return
+	}
+
+	methods := m.Methods(r.URL.Path)
+	if len(methods) == 0 {
+		writeError(w, http.StatusNotFound, CodeNotFound, "no plugin api matches path "+r.URL.Path)
+		return
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method "+r.Method+" is not allowed on path "+r.URL.Path)
+}
+
+// lookup returns the most specific route of method matches path.
+func (m *Mux) lookup(method string, path string) (route, map[string]string, bool) {
+	var (
+		best       route
+		bestParams map[string]string
+		found      bool
+	)
+	for _, r := range m.routes {
+		if r.Method != method {
+			continue
+		}
+		params, ok := match(r.segments, path)
+		if !ok {
+			continue
+		}
+		if !found || moreSpecific(r.segments, best.segments) {
+			best, bestParams, found = r, params, true
+		}
+	}
+	return best, bestParams, found
+}
+
+func parsePattern(pattern string) ([]segment, error) {
+	var segments []segment
+	parts := splitPath(pattern)
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			segments = append(segments, segment{kind: segmentParam, value: part[1:]})
+		case strings.HasPrefix(part, "*"):
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("wildcard segment %s must be the last segment", part)
+			}
+			segments = append(segments, segment{kind: segmentWildcard, value: part[1:]})
+		default:
+			segments = append(segments, segment{kind: segmentStatic, value: part})
+		}
+	}
+	return segments, nil
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// match reports whether path matches segments and returns the matched params.
+// The value of wildcard starts with "/" as Gin does.
+func match(segments []segment, path string) (map[string]string, bool) {
+	parts := splitPath(path)
+	params := map[string]string{}
+	for i, seg := range segments {
+		if seg.kind == segmentWildcard {
+			params[seg.value] = "/" + strings.Join(parts[i:], "/")
+			return params, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		switch seg.kind {
+		case segmentStatic:
+			if parts[i] != seg.value {
+				return nil, false
+			}
+		case segmentParam:
+			if parts[i] == "" {
+				return nil, false
+			}
+			params[seg.value] = parts[i]
+		}
+	}
+	if len(parts) != len(segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+// moreSpecific reports whether a is more specific than b by comparing the
+// kinds of their segments from left to right.
+func moreSpecific(a []segment, b []segment) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].kind != b[i].kind {
+			return a[i].kind < b[i].kind
+		}
+	}
+	return len(a) > len(b)
+}
+
+func writeError(w http.ResponseWriter, status int, code int, message string) {
+	pluginapi.WriteJSON(w, status, protocol.Error(code, message))
+}
@@ -0,0 +1,141 @@
+package httpmux
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/pluginapi"
+)
+
+func writeRoute(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Route", name)
+		_, _ = w.Write([]byte(pluginapi.Param(r, "id") + pluginapi.Param(r, "path")))
+	}
+}
+
+func newTestMux() *Mux {
+	return New(func(r pluginapi.Router) {
+		r.Group("/tasks", func(r pluginapi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Group", "tasks")
+					next.ServeHTTP(w, r)
+				})
+			})
+			r.GET("", writeRoute("list"))
+			r.POST("", writeRoute("create"))
+			r.GET("/:id", writeRoute("get"))
+			r.DELETE("/:id", writeRoute("delete"))
+			r.GET("/latest", writeRoute("latest"))
+		})
+		r.GET("/files/*path", writeRoute("files"))
+	})
+}
+
+func TestMuxServeHTTP(t *testing.T) {
+	mux := newTestMux()
+
+	var cases = []struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedRoute  string
+		expectedBody   string
+	}{
+		{http.MethodGet, "/tasks", http.StatusOK, "list", ""},
+		{http.MethodGet, "/tasks/", http.StatusOK, "list", ""},
+		{http.MethodPost, "/tasks", http.StatusOK, "create", ""},
+		{http.MethodGet, "/tasks/42", http.StatusOK, "get", "42"},
+		{http.MethodDelete, "/tasks/42", http.StatusOK, "delete", "42"},
+		{http.MethodHead, "/tasks/42", http.StatusOK, "get", "42"},
+		{http.MethodGet, "/tasks/latest", http.StatusOK, "latest", ""},
+		{http.MethodGet, "/files/a/b.txt", http.StatusOK, "files", "/a/b.txt"},
+		{http.MethodGet, "/files", http.StatusOK, "files", "/"},
+		{http.MethodGet, "/tasks/42/logs", http.StatusNotFound, "", `{"result":false,"code":40400,"message":"no plugin api matches path /tasks/42/logs","data":null}`},
+		{http.MethodPut, "/tasks/42", http.StatusMethodNotAllowed, "", `{"result":false,"code":40500,"message":"method PUT is not allowed on path /tasks/42","data":null}`},
+		{http.MethodOptions, "/tasks/42", http.StatusNoContent, "", ""},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, nil))
+
+		assert.Equal(t, c.expectedStatus, rec.Code, c.method+" "+c.path)
+		assert.Equal(t, c.expectedRoute, rec.Header().Get("X-Route"), c.method+" "+c.path)
+		if rec.Header().Get("Content-Type") == "application/json" {
+			assert.JSONEq(t, c.expectedBody, rec.Body.String(), c.method+" "+c.path)
+		} else {
+			assert.Equal(t, c.expectedBody, rec.Body.String(), c.method+" "+c.path)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/42", nil))
+	assert.Equal(t, "tasks", rec.Header().Get("X-Group"))
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/tasks/42", nil))
+	assert.Equal(t, "DELETE, GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+}
+
+func TestMuxMethods(t *testing.T) {
+	mux := newTestMux()
+
+	assert.Equal(t, []string{"GET", "HEAD", "OPTIONS", "POST"}, mux.Methods("/tasks"))
+	assert.Equal(t, []string{"DELETE", "GET", "HEAD", "OPTIONS"}, mux.Methods("/tasks/latest"))
+	assert.Nil(t, mux.Methods("/unknown"))
+	assert.Len(t, mux.Routes(), 6)
+}
+
+func TestNewRejectsWildcardNotLast(t *testing.T) {
+	assert.PanicsWithError(t, "invalid plugin api route GET /files/*path/raw: wildcard segment *path must be the last segment", func() {
+		New(func(r pluginapi.Router) {
+			r.GET("/files/*path/raw", writeRoute("raw"))
+		})
+	})
+}
+
+func TestDefault(t *testing.T) {
+	pluginapi.ResetForTest()
+	t.Cleanup(pluginapi.ResetForTest)
+	pluginapi.Register(func(r pluginapi.Router) {
+		r.GET("/ping", writeRoute("ping"))
+	})
+
+	server := httptest.NewServer(Default())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/ping")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ping", resp.Header.Get("X-Route"))
+}
+
+type remotePlugin struct{}
+
+func (remotePlugin) Version() string            { return "16.0.0" }
+func (remotePlugin) Desc() string               { return "remote plugin" }
+func (remotePlugin) Execute(*kit.Context) error { return nil }
+
+func TestDefaultChecksRemoteOptions(t *testing.T) {
+	pluginapi.ResetForTest()
+	t.Cleanup(pluginapi.ResetForTest)
+	form, err := json.Marshal(kit.Form{"host": kit.RemoteOptions("/hosts")})
+	assert.NoError(t, err)
+	hub.MustInstallV2(remotePlugin{}, hub.PluginSpec{Form: form})
+
+	assert.PanicsWithError(t, "version 16.0.0 form field host: remote options refer to unregistered plugin api GET /hosts", func() { Default() })
+
+	pluginapi.RegisterForVersion("16.0.0", func(r pluginapi.Router) {
+		r.GET("/hosts", writeRoute("hosts"))
+	})
+	assert.NotPanics(t, func() { Default() })
+}
@@ -0,0 +1,194 @@
+package pluginapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/internal/schema"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+// These codes are written to the response envelope of JSON handlers.
+const (
+	CodeInvalidRequest  = 40000
+	CodeRequestTooLarge = 41300
+	CodeInternalError   = 50000
+)
+
+// DefaultMaxBodySize is the default limit of JSON request body size in bytes.
+const DefaultMaxBodySize = 1 << 20
+
+// internalErrorMessage is written instead of the message of errors which are
+// not *Error, so internal details are not exposed to API callers.
+const internalErrorMessage = "internal error"
+
+// An Error is returned by JSON handler functions to control the HTTP status
+// and envelope code of the response.
+type Error struct {
+	Status  int
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError returns a new Error instance.
+func NewError(status int, code int, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Validator is implemented by JSON request types which validate themselves
+// after decoding.
+type Validator interface {
+	Validate() error
+}
+
+// JSONOption customizes the handler returned by JSON.
+type JSONOption func(*jsonOptions)
+
+type jsonOptions struct {
+	validateSchema bool
+	maxBodySize    int64
+}
+
+// WithSchemaValidation validates the request body against the JSON schema
+// reflected from the request type before decoding.
+func WithSchemaValidation() JSONOption {
+	return func(o *jsonOptions) {
+		o.validateSchema = true
+	}
+}
+
+// WithMaxBodySize sets the limit of request body size in bytes, larger
+// requests are answered with CodeRequestTooLarge. The limit is
+// DefaultMaxBodySize by default, a non-positive size disables it.
+func WithMaxBodySize(size int64) JSONOption {
+	return func(o *jsonOptions) {
+		o.maxBodySize = size
+	}
+}
+
+// JSON returns a plugin API handler which decodes the JSON request body into
+// Req, validates it and writes the Resp returned by fn in the protocol.OK
+// envelope.
+//
+// An empty request body leaves Req as its zero value. Decode and validation
+// failures are answered with CodeInvalidRequest, errors returned by fn are
+// answered with their Status and Code if they are *Error, or
+// CodeInternalError otherwise, whose message is logged instead of written to
+// the response.
+func JSON[Req any, Resp any](fn func(r *http.Request, req *Req) (Resp, error), opts ...JSONOption) http.HandlerFunc {
+	options := jsonOptions{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var requestSchema map[string]interface{}
+	if options.validateSchema {
+		requestSchema = mustReflectRequestSchema(new(Req))
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := new(Req)
+		if err := decodeRequest(r, req, requestSchema, options.maxBodySize); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		resp, err := fn(r, req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, protocol.OK(resp))
+	}
+}
+
+// WriteJSON writes v as the JSON response body with status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// readBody reads the request body, which should not be larger than limit
+// bytes unless limit is non-positive.
+func readBody(body io.Reader, limit int64) ([]byte, error) {
+	if limit > 0 {
+		// read one more byte to find out bodies larger than limit
+		body = io.LimitReader(body, limit+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, NewError(http.StatusBadRequest, CodeInvalidRequest, "read request body failed: "+err.Error())
+	}
+	if limit > 0 && int64(len(data)) > limit {
+		return nil, NewError(http.StatusRequestEntityTooLarge, CodeRequestTooLarge,
+			fmt.Sprintf("request body is larger than %d bytes", limit))
+	}
+	return data, nil
+}
+
+func decodeRequest(r *http.Request, req interface{}, requestSchema map[string]interface{}, maxBodySize int64) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = readBody(r.Body, maxBodySize); err != nil {
+			return err
+		}
+	}
+	if len(body) == 0 {
+		body = []byte("{}")
+	}
+
+	if requestSchema != nil {
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			return NewError(http.StatusBadRequest, CodeInvalidRequest, "invalid request body: "+err.Error())
+		}
+		if err := schema.Validate(requestSchema, value); err != nil {
+			return NewError(http.StatusBadRequest, CodeInvalidRequest, "invalid request: "+err.Error())
+		}
+	}
+
+	if err := json.Unmarshal(body, req); err != nil {
+		return NewError(http.StatusBadRequest, CodeInvalidRequest, "invalid request body: "+err.Error())
+	}
+
+	if validator, ok := req.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return NewError(http.StatusBadRequest, CodeInvalidRequest, "invalid request: "+err.Error())
+		}
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := asError(r, err)
+	WriteJSON(w, apiErr.Status, protocol.Error(apiErr.Code, apiErr.Message))
+}
+
+// asError returns err if it is an *Error, otherwise it logs err and returns
+// an internal error without its message.
+func asError(r *http.Request, err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	Logger(r).Errorf("plugin api handler err: %v", err)
+	return NewError(http.StatusInternalServerError, CodeInternalError, internalErrorMessage)
+}
+
+func mustReflectRequestSchema(req interface{}) map[string]interface{} {
+	requestSchema, err := hub.ReflectSchema(req)
+	if err != nil {
+		panic(err)
+	}
+	return requestSchema
+}
@@ -0,0 +1,128 @@
+package pluginapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+type createTaskRequest struct {
+	Name  string `json:"name"`
+	Count int    `json:"count,omitempty" jsonschema:"minimum=1"`
+}
+
+func (r *createTaskRequest) Validate() error {
+	if r.Name == "forbidden" {
+		return fmt.Errorf("name %s is forbidden", r.Name)
+	}
+	return nil
+}
+
+type createTaskResponse struct {
+	ID string `json:"id"`
+}
+
+func createTask(r *http.Request, req *createTaskRequest) (createTaskResponse, error) {
+	switch req.Name {
+	case "conflict":
+		return createTaskResponse{}, NewError(http.StatusConflict, 40900, "task already exists")
+	case "broken":
+		return createTaskResponse{}, fmt.Errorf("store down")
+	}
+	return createTaskResponse{ID: "task-" + req.Name}, nil
+}
+
+func TestJSONHandler(t *testing.T) {
+	handler := JSON(createTask)
+
+	var cases = []struct {
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{`{"name": "demo"}`, http.StatusOK, `{"result":true,"code":0,"message":"success","data":{"id":"task-demo"}}`},
+		{``, http.StatusOK, `{"result":true,"code":0,"message":"success","data":{"id":"task-"}}`},
+		{`{"name": `, http.StatusBadRequest, `{"result":false,"code":40000,"message":"invalid request body: unexpected end of JSON input","data":null}`},
+		{`{"name": "forbidden"}`, http.StatusBadRequest, `{"result":false,"code":40000,"message":"invalid request: name forbidden is forbidden","data":null}`},
+		{`{"name": "conflict"}`, http.StatusConflict, `{"result":false,"code":40900,"message":"task already exists","data":null}`},
+		{`{"name": "broken"}`, http.StatusInternalServerError, `{"result":false,"code":50000,"message":"internal error","data":null}`},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(c.body)))
+
+		assert.Equal(t, c.expectedStatus, rec.Code, c.body)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, c.expectedBody, rec.Body.String(), c.body)
+	}
+}
+
+func TestJSONHandlerLogsInternalErrorWithRequestLogger(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{"name": "broken"}`))
+	req = WithLogger(WithCaller(req, kit.Caller{AppCode: "bk_sops"}), log.NewEntry(logger).WithField("trace_id", "trace-1"))
+
+	JSON(createTask)(httptest.NewRecorder(), req)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "plugin api handler err: store down", entry.Message)
+	assert.Equal(t, "trace-1", entry.Data["trace_id"])
+	assert.Equal(t, "bk_sops", entry.Data["caller_app"])
+	assert.Equal(t, "/tasks", entry.Data["path"])
+}
+
+func TestJSONHandlerMaxBodySize(t *testing.T) {
+	body := `{"name": "demo"}`
+
+	rec := httptest.NewRecorder()
+	JSON(createTask, WithMaxBodySize(8))(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.JSONEq(t, `{"result":false,"code":41300,"message":"request body is larger than 8 bytes","data":null}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	JSON(createTask, WithMaxBodySize(int64(len(body))))(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	large := `{"name": "` + strings.Repeat("a", DefaultMaxBodySize) + `"}`
+	JSON(createTask)(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(large)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	JSON(createTask, WithMaxBodySize(0))(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(large)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestJSONHandlerWithSchemaValidation(t *testing.T) {
+	handler := JSON(createTask, WithSchemaValidation())
+
+	var cases = []struct {
+		body            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{`{"name": "demo", "count": 2}`, http.StatusOK, "success"},
+		{`{"count": 2}`, http.StatusBadRequest, "invalid request: name: is required"},
+		{`{"name": "demo", "count": 0}`, http.StatusBadRequest, "invalid request: count: should be greater than minimum 1"},
+		{`{"name": "demo", "extra": true}`, http.StatusBadRequest, "invalid request: extra: is not allowed"},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(c.body)))
+
+		assert.Equal(t, c.expectedStatus, rec.Code, c.body)
+		assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":%q`, c.expectedMessage), c.body)
+	}
+}
@@ -0,0 +1,101 @@
+package pluginapi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+// ResolveRoute returns the full path of the route registered with method and
+// path for version, the route registered by RegisterForVersion takes
+// precedence over the one registered by Register. The bool result reports
+// whether such a route is registered.
+//
+// ResolveRoute collects the routes on every call, use the resolver set by
+// DetailOptions to resolve the routes of many form fields.
+func ResolveRoute(version string, method string, path string) (string, bool) {
+	return newRouteResolver()(version, method, path)
+}
+
+// newRouteResolver returns a resolver like ResolveRoute, which collects the
+// routes registered by Register once and the routes of each version once.
+func newRouteResolver() func(version string, method string, path string) (string, bool) {
+	var global map[string]bool
+	versions := map[string]map[string]bool{}
+	return func(version string, method string, path string) (string, bool) {
+		versioned, found := versions[version]
+		if !found {
+			versioned = routeKeys(VersionRoutes(version))
+			versions[version] = versioned
+		}
+		if versioned[routeKey(method, VersionPrefix(version)+path)] {
+			return VersionPrefix(version) + path, true
+		}
+
+		if global == nil {
+			mu.RLock()
+			registered := make([]Registrar, len(registrars))
+			copy(registered, registrars)
+			mu.RUnlock()
+			global = routeKeys(CollectRoutes(registered))
+		}
+		if global[routeKey(method, path)] {
+			return path, true
+		}
+		return "", false
+	}
+}
+
+func routeKeys(routes []Route) map[string]bool {
+	keys := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		keys[routeKey(route.Method, route.Path)] = true
+	}
+	return keys
+}
+
+// CheckRemoteOptions checks that the plugin APIs referred by form remote
+// options of all installed versions are registered. It is called by
+// httpmux.Default, runtimes not serving plugin APIs by httpmux should call it
+// at startup after versions are installed and plugin APIs are registered.
+func CheckRemoteOptions() error {
+	resolve := newRouteResolver()
+	versions := hub.GetPluginVersions()
+	for _, version := range versions {
+		detail, err := hub.GetPluginDetail(version)
+		if err != nil {
+			return err
+		}
+		if !detail.FormsRenderFormEnabled() {
+			continue
+		}
+
+		form := detail.FormsRenderFormJSON()
+		fields := make([]string, 0, len(form))
+		for field := range form {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			attrs, ok := form[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			source, found, err := kit.RemoteOptionsOf(attrs)
+			if err != nil {
+				return fmt.Errorf("version %s form field %s: %v", version, field, err)
+			}
+			if !found {
+				continue
+			}
+			if _, ok := resolve(version, source.Method, source.Path); !ok {
+				return fmt.Errorf("version %s form field %s: remote options refer to unregistered plugin api %s %s",
+					version, field, source.Method, source.Path)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package pluginapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+func TestResolveRoute(t *testing.T) {
+	ResetForTest()
+	t.Cleanup(ResetForTest)
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	Register(func(router Router) {
+		router.GET("/hosts", noop)
+		router.GET("/modules", noop)
+	})
+	RegisterForVersion("15.0.1", func(router Router) {
+		router.GET("/hosts", noop)
+	})
+
+	var cases = []struct {
+		version       string
+		method        string
+		path          string
+		expectedPath  string
+		expectedFound bool
+	}{
+		{"15.0.0", "GET", "/hosts", "/hosts", true},
+		{"15.0.1", "get", "/hosts", "/versions/15.0.1/hosts", true},
+		{"15.0.1", "GET", "/modules", "/modules", true},
+		{"15.0.1", "POST", "/hosts", "", false},
+		{"15.0.1", "GET", "/sets", "", false},
+	}
+
+	for _, c := range cases {
+		path, found := ResolveRoute(c.version, c.method, c.path)
+		assert.Equal(t, c.expectedPath, path, c)
+		assert.Equal(t, c.expectedFound, found, c)
+	}
+}
+
+func TestCheckRemoteOptions(t *testing.T) {
+	ResetForTest()
+	t.Cleanup(ResetForTest)
+
+	form, err := json.Marshal(kit.Form{"host": kit.RemoteOptions("/hosts")})
+	require.NoError(t, err)
+	hub.MustInstallV2(versionTestPlugin{version: "15.1.0"}, hub.PluginSpec{Form: form})
+
+	assert.EqualError(t, CheckRemoteOptions(), "version 15.1.0 form field host: remote options refer to unregistered plugin api GET /hosts")
+	_, err = protocol.BuildDetail("15.1.0", DetailOptions("15.1.0", protocol.DetailOptions{}))
+	assert.EqualError(t, err, "form field host: remote options refer to unregistered plugin api GET /hosts")
+
+	RegisterForVersion("15.1.0", func(router Router) {
+		router.GET("/hosts", func(w http.ResponseWriter, r *http.Request) {})
+	})
+	assert.NoError(t, CheckRemoteOptions())
+
+	data, err := protocol.BuildDetail("15.1.0", DetailOptions("15.1.0", protocol.DetailOptions{}))
+	require.NoError(t, err)
+	raw, err := json.Marshal(data.Forms.RenderForm)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"url":"/bk_plugin/plugin_api/versions/15.1.0/hosts"`)
+}
+
+func TestDetailOptionsCollectRoutesOnce(t *testing.T) {
+	ResetForTest()
+	t.Cleanup(ResetForTest)
+
+	collected := 0
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	Register(func(router Router) {
+		collected++
+		router.GET("/hosts", noop)
+		router.GET("/modules", noop)
+	})
+	form, err := json.Marshal(kit.Form{"host": kit.RemoteOptions("/hosts"), "module": kit.RemoteOptions("/modules")})
+	require.NoError(t, err)
+	hub.MustInstallV2(versionTestPlugin{version: "15.2.0"}, hub.PluginSpec{Form: form})
+
+	_, err = protocol.BuildDetail("15.2.0", DetailOptions("15.2.0", protocol.DetailOptions{}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, collected)
+
+	require.NoError(t, CheckRemoteOptions())
+	assert.Equal(t, 2, collected)
+}
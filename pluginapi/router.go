@@ -7,18 +7,40 @@ import (
 )
 
 // Router is the framework-owned route registration abstraction for plugin APIs.
+//
+// Use appends middlewares applied to the routes registered after it on this
+// router and its groups. Group registers routes under prefix with a child
+// router, which inherits the middlewares of its parent; middlewares used in
+// the child router do not affect the parent.
 type Router interface {
+	Use(middlewares ...Middleware)
+	Group(prefix string, fn func(Router))
 	Handle(method string, path string, handler http.HandlerFunc)
 	GET(path string, handler http.HandlerFunc)
 	POST(path string, handler http.HandlerFunc)
+	PUT(path string, handler http.HandlerFunc)
+	PATCH(path string, handler http.HandlerFunc)
+	DELETE(path string, handler http.HandlerFunc)
+}
+
+// Middleware wraps a plugin API handler, e.g. for auth, logging or rate limit.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler with middlewares, the first middleware is the outermost.
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
 }
 
 // Registrar registers one or more plugin API routes.
 type Registrar func(Router)
 
 var (
-	mu         sync.RWMutex
-	registrars []Registrar
+	mu                sync.RWMutex
+	registrars        []Registrar
+	versionRegistrars []versionRegistrar
 )
 
 // Register stores a plugin API registrar. Runtime implementations decide how to
@@ -29,12 +51,16 @@ func Register(registrar Registrar) {
 	registrars = append(registrars, registrar)
 }
 
-// Registrars returns a copy of registered plugin API registrars.
+// Registrars returns a copy of registered plugin API registrars, followed by
+// the version-scoped registrars mounted under their version prefix.
 func Registrars() []Registrar {
 	mu.RLock()
 	defer mu.RUnlock()
-	copied := make([]Registrar, len(registrars))
+	copied := make([]Registrar, len(registrars), len(registrars)+len(versionRegistrars))
 	copy(copied, registrars)
+	for _, r := range versionRegistrars {
+		copied = append(copied, r.mounted())
+	}
 	return copied
 }
 
@@ -43,4 +69,5 @@ func ResetForTest() {
 	mu.Lock()
 	defer mu.Unlock()
 	registrars = nil
+	versionRegistrars = nil
 }
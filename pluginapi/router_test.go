@@ -9,7 +9,9 @@ import (
 )
 
 type recordingRouter struct {
-	routes []recordedRoute
+	prefix      string
+	middlewares []Middleware
+	routes      *[]recordedRoute
 }
 
 type recordedRoute struct {
@@ -18,8 +20,28 @@ type recordedRoute struct {
 	handler http.HandlerFunc
 }
 
+func newRecordingRouter() *recordingRouter {
+	return &recordingRouter{routes: &[]recordedRoute{}}
+}
+
+func (r *recordingRouter) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+func (r *recordingRouter) Group(prefix string, fn func(Router)) {
+	fn(&recordingRouter{
+		prefix:      r.prefix + prefix,
+		middlewares: append([]Middleware{}, r.middlewares...),
+		routes:      r.routes,
+	})
+}
+
 func (r *recordingRouter) Handle(method string, path string, handler http.HandlerFunc) {
-	r.routes = append(r.routes, recordedRoute{method: method, path: path, handler: handler})
+	*r.routes = append(*r.routes, recordedRoute{
+		method:  method,
+		path:    r.prefix + path,
+		handler: Chain(handler, r.middlewares...).ServeHTTP,
+	})
 }
 
 func (r *recordingRouter) GET(path string, handler http.HandlerFunc) {
@@ -30,6 +52,27 @@ func (r *recordingRouter) POST(path string, handler http.HandlerFunc) {
 	r.Handle(http.MethodPost, path, handler)
 }
 
+func (r *recordingRouter) PUT(path string, handler http.HandlerFunc) {
+	r.Handle(http.MethodPut, path, handler)
+}
+
+func (r *recordingRouter) PATCH(path string, handler http.HandlerFunc) {
+	r.Handle(http.MethodPatch, path, handler)
+}
+
+func (r *recordingRouter) DELETE(path string, handler http.HandlerFunc) {
+	r.Handle(http.MethodDelete, path, handler)
+}
+
+func headerMiddleware(value string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Middleware", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func TestRegisterStoresHTTPRegistrars(t *testing.T) {
 	ResetForTest()
 	t.Cleanup(ResetForTest)
@@ -38,15 +81,16 @@ func TestRegisterStoresHTTPRegistrars(t *testing.T) {
 		router.GET("/echo", func(w http.ResponseWriter, r *http.Request) {})
 	})
 
-	router := &recordingRouter{}
+	router := newRecordingRouter()
 	for _, registrar := range Registrars() {
 		registrar(router)
 	}
 
-	assert.Len(t, router.routes, 1)
-	assert.Equal(t, http.MethodGet, router.routes[0].method)
-	assert.Equal(t, "/echo", router.routes[0].path)
-	assert.NotNil(t, router.routes[0].handler)
+	routes := *router.routes
+	assert.Len(t, routes, 1)
+	assert.Equal(t, http.MethodGet, routes[0].method)
+	assert.Equal(t, "/echo", routes[0].path)
+	assert.NotNil(t, routes[0].handler)
 }
 
 func TestRegistrarsReturnsCopy(t *testing.T) {
@@ -60,11 +104,106 @@ func TestRegistrarsReturnsCopy(t *testing.T) {
 		router.POST("/mutated", func(w http.ResponseWriter, r *http.Request) {})
 	}
 
-	router := &recordingRouter{}
+	router := newRecordingRouter()
 	for _, registrar := range Registrars() {
 		registrar(router)
 	}
-	assert.Empty(t, router.routes)
+	assert.Empty(t, *router.routes)
+}
+
+func TestRouterMethodsGroupsAndMiddlewares(t *testing.T) {
+	ResetForTest()
+	t.Cleanup(ResetForTest)
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	Register(func(router Router) {
+		router.Use(headerMiddleware("root"))
+		router.PUT("/tasks/:id", noop)
+		router.Group("/admin", func(group Router) {
+			group.Use(headerMiddleware("admin"))
+			group.PATCH("/tasks/:id", noop)
+			group.Group("/v2", func(group Router) {
+				group.DELETE("/tasks/:id", noop)
+			})
+		})
+		router.GET("/tasks", noop)
+	})
+
+	router := newRecordingRouter()
+	for _, registrar := range Registrars() {
+		registrar(router)
+	}
+
+	var cases = []struct {
+		method      string
+		path        string
+		middlewares []string
+	}{
+		{http.MethodPut, "/tasks/:id", []string{"root"}},
+		{http.MethodPatch, "/admin/tasks/:id", []string{"root", "admin"}},
+		{http.MethodDelete, "/admin/v2/tasks/:id", []string{"root", "admin"}},
+		{http.MethodGet, "/tasks", []string{"root"}},
+	}
+
+	routes := *router.routes
+	assert.Len(t, routes, len(cases))
+	for i, c := range cases {
+		assert.Equal(t, c.method, routes[i].method)
+		assert.Equal(t, c.path, routes[i].path)
+
+		rec := httptest.NewRecorder()
+		routes[i].handler(rec, httptest.NewRequest(c.method, c.path, nil))
+		assert.Equal(t, c.middlewares, rec.Header().Values("X-Middleware"))
+	}
+}
+
+func TestRoutesCollectsRegisteredRoutesAndOperations(t *testing.T) {
+	ResetForTest()
+	t.Cleanup(ResetForTest)
+
+	Register(func(router Router) {
+		router.Group("/tasks", func(group Router) {
+			group.Use(headerMiddleware("tasks"))
+			group.GET("/:id", func(w http.ResponseWriter, r *http.Request) {})
+			Describe(group, http.MethodGet, "/:id", Operation{Summary: "Get task"})
+		})
+		router.POST("/tasks", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	routes := Routes()
+	assert.Len(t, routes, 2)
+	assert.Equal(t, http.MethodGet, routes[0].Method)
+	assert.Equal(t, "/tasks/:id", routes[0].Path)
+	assert.Equal(t, Operation{Summary: "Get task"}, routes[0].Operation)
+	assert.Equal(t, http.MethodPost, routes[1].Method)
+	assert.Equal(t, Operation{}, routes[1].Operation)
+
+	rec := httptest.NewRecorder()
+	routes[0].Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/1", nil))
+	assert.Equal(t, []string{"tasks"}, rec.Header().Values("X-Middleware"))
+
+	// Describe is a no-op for routers which do not collect documents
+	assert.NotPanics(t, func() { Describe(newRecordingRouter(), http.MethodGet, "/", Operation{}) })
+}
+
+func TestCollectRoutesScopesRootMiddlewaresToRegistrar(t *testing.T) {
+	routes := CollectRoutes([]Registrar{
+		func(router Router) {
+			router.Use(headerMiddleware("first"))
+			router.GET("/first", func(w http.ResponseWriter, r *http.Request) {})
+		},
+		func(router Router) {
+			router.GET("/second", func(w http.ResponseWriter, r *http.Request) {})
+		},
+	})
+
+	assert.Len(t, routes, 2)
+	rec := httptest.NewRecorder()
+	routes[0].Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/first", nil))
+	assert.Equal(t, []string{"first"}, rec.Header().Values("X-Middleware"))
+	rec = httptest.NewRecorder()
+	routes[1].Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/second", nil))
+	assert.Empty(t, rec.Header().Values("X-Middleware"))
 }
 
 func TestParamReadsRegisteredPathParams(t *testing.T) {
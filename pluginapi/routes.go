@@ -0,0 +1,123 @@
+package pluginapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Operation describes a plugin API route for API documents.
+//
+// Request and Response are values of the request body and response data
+// types, their JSON schema is reflected when generating documents.
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Request     interface{}
+	Response    interface{}
+}
+
+// Describer is an optional interface implemented by Router which collects
+// route documents.
+type Describer interface {
+	Describe(method string, path string, op Operation)
+}
+
+// Describe attaches op to the route registered by method and path on router.
+// It is a no-op if router does not implement Describer.
+func Describe(router Router, method string, path string, op Operation) {
+	if describer, ok := router.(Describer); ok {
+		describer.Describe(method, path, op)
+	}
+}
+
+// Route is a plugin API route collected from registrars.
+type Route struct {
+	Method    string
+	Path      string
+	Handler   http.Handler
+	Operation Operation
+}
+
+// Routes runs all registered registrars and returns the routes they register
+// in registration order.
+func Routes() []Route {
+	return CollectRoutes(Registrars())
+}
+
+// CollectRoutes runs registrars and returns the routes they register in
+// registration order.
+//
+// Each registrar gets its own root router, so middlewares it adds with Use
+// only apply to its own routes.
+func CollectRoutes(registrars []Registrar) []Route {
+	state := &collectorState{operations: map[string]Operation{}}
+	for _, registrar := range registrars {
+		registrar(&routeCollector{state: state})
+	}
+
+	routes := state.routes
+	for i := range routes {
+		routes[i].Operation = state.operations[routeKey(routes[i].Method, routes[i].Path)]
+	}
+	return routes
+}
+
+type collectorState struct {
+	routes     []Route
+	operations map[string]Operation
+}
+
+type routeCollector struct {
+	prefix      string
+	middlewares []Middleware
+	state       *collectorState
+}
+
+func routeKey(method string, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+func (c *routeCollector) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+func (c *routeCollector) Group(prefix string, fn func(Router)) {
+	fn(&routeCollector{
+		prefix:      c.prefix + prefix,
+		middlewares: append([]Middleware{}, c.middlewares...),
+		state:       c.state,
+	})
+}
+
+func (c *routeCollector) Describe(method string, path string, op Operation) {
+	c.state.operations[routeKey(method, c.prefix+path)] = op
+}
+
+func (c *routeCollector) Handle(method string, path string, handler http.HandlerFunc) {
+	c.state.routes = append(c.state.routes, Route{
+		Method:  strings.ToUpper(method),
+		Path:    c.prefix + path,
+		Handler: Chain(handler, c.middlewares...),
+	})
+}
+
+func (c *routeCollector) GET(path string, handler http.HandlerFunc) {
+	c.Handle(http.MethodGet, path, handler)
+}
+
+func (c *routeCollector) POST(path string, handler http.HandlerFunc) {
+	c.Handle(http.MethodPost, path, handler)
+}
+
+func (c *routeCollector) PUT(path string, handler http.HandlerFunc) {
+	c.Handle(http.MethodPut, path, handler)
+}
+
+func (c *routeCollector) PATCH(path string, handler http.HandlerFunc) {
+	c.Handle(http.MethodPatch, path, handler)
+}
+
+func (c *routeCollector) DELETE(path string, handler http.HandlerFunc) {
+	c.Handle(http.MethodDelete, path, handler)
+}
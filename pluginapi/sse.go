@@ -0,0 +1,191 @@
+package pluginapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+// DefaultHeartbeatInterval is the interval of SSE heartbeat comments, which
+// keep the connection alive through proxies.
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// Event is a server-sent event.
+//
+// Data of string or []byte type is sent as is, other values are sent in JSON
+// format. Empty Event, ID and zero Retry are omitted.
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// SSEOption customizes the handler returned by SSE.
+type SSEOption func(*sseOptions)
+
+type sseOptions struct {
+	heartbeat time.Duration
+}
+
+// WithHeartbeat sets the interval of heartbeat comments, a non-positive
+// interval disables heartbeat.
+func WithHeartbeat(interval time.Duration) SSEOption {
+	return func(o *sseOptions) {
+		o.heartbeat = interval
+	}
+}
+
+// Stream sends server-sent events to the client of a request.
+type Stream struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// Context returns the request context, which is done when the client
+// disconnects.
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// Send writes e to the client and flushes it. It returns the context error
+// once the client disconnects, and an error without writing anything if the
+// ID or Event of e contains a line break, which would inject other fields.
+func (s *Stream) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n") {
+		return fmt.Errorf("event id %q contains line break", e.ID)
+	}
+	if strings.ContainsAny(e.Event, "\r\n") {
+		return fmt.Errorf("event name %q contains line break", e.Event)
+	}
+	data, err := encodeEventData(e.Data)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	// SSE ends lines with \r\n, \r or \n, split data by all of them so every
+	// line is sent as a data field
+	data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+func (s *Stream) heartbeat() error {
+	return s.write(": heartbeat\n\n")
+}
+
+func (s *Stream) write(data string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write([]byte(data)); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// SSE returns a handler streaming server-sent events sent by fn.
+//
+// The handler writes the event stream headers before calling fn, and sends
+// heartbeat comments every DefaultHeartbeatInterval until fn returns or the
+// client disconnects. A non-nil error returned by fn is sent as an "error"
+// event whose data is the standard envelope, with the Code and Message of
+// *Error, or CodeInternalError and a generic message otherwise; errors caused
+// by client disconnection are dropped. Response writers not implementing
+// http.Flusher get a 500 response.
+func SSE(fn func(r *http.Request, stream *Stream) error, opts ...SSEOption) http.HandlerFunc {
+	options := sseOptions{heartbeat: DefaultHeartbeatInterval}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, r, NewError(http.StatusInternalServerError, CodeInternalError, "streaming is not supported by response writer"))
+			return
+		}
+
+		header := w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stream := &Stream{ctx: ctx, w: w, flusher: flusher}
+
+		var wg sync.WaitGroup
+		if options.heartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(options.heartbeat)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if stream.heartbeat() != nil {
+							return
+						}
+					}
+				}
+			}()
+		}
+
+		err := fn(r.WithContext(ctx), stream)
+		if err != nil && r.Context().Err() == nil && !errors.Is(err, context.Canceled) {
+			apiErr := asError(r, err)
+			_ = stream.Send(Event{Event: "error", Data: protocol.Error(apiErr.Code, apiErr.Message)})
+		}
+		cancel()
+		wg.Wait()
+	}
+}
+
+func encodeEventData(data interface{}) (string, error) {
+	switch data := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return data, nil
+	case []byte:
+		return string(data), nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
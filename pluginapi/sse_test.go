@@ -0,0 +1,115 @@
+package pluginapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hostOption struct {
+	IP string `json:"ip"`
+}
+
+type nonFlushingWriter struct {
+	http.ResponseWriter
+}
+
+func TestSSE(t *testing.T) {
+	handler := SSE(func(r *http.Request, stream *Stream) error {
+		assert.NoError(t, stream.Send(Event{ID: "1", Event: "option", Data: hostOption{IP: "127.0.0.1"}, Retry: time.Second}))
+		assert.NoError(t, stream.Send(Event{Data: "line 1\nline 2"}))
+		return NewError(http.StatusConflict, 40900, "preview expired")
+	}, WithHeartbeat(0))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/options", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "id: 1\nevent: option\nretry: 1000\ndata: {\"ip\":\"127.0.0.1\"}\n\n"+
+		"data: line 1\ndata: line 2\n\n"+
+		"event: error\ndata: {\"result\":false,\"code\":40900,\"message\":\"preview expired\",\"data\":null}\n\n",
+		rec.Body.String())
+}
+
+func TestSSERejectsLineBreakInFields(t *testing.T) {
+	handler := SSE(func(r *http.Request, stream *Stream) error {
+		assert.EqualError(t, stream.Send(Event{ID: "1\ndata: injected", Data: "a"}), `event id "1\ndata: injected" contains line break`)
+		assert.EqualError(t, stream.Send(Event{Event: "option\r\n\nevent: other", Data: "a"}), `event name "option\r\n\nevent: other" contains line break`)
+		return nil
+	}, WithHeartbeat(0))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/options", nil))
+
+	assert.Empty(t, rec.Body.String())
+
+	handler = SSE(func(r *http.Request, stream *Stream) error {
+		return stream.Send(Event{Data: "a\rid: 2\r\nb"})
+	}, WithHeartbeat(0))
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/options", nil))
+	assert.Equal(t, "data: a\ndata: id: 2\ndata: b\n\n", rec.Body.String())
+}
+
+func TestSSEInternalError(t *testing.T) {
+	handler := SSE(func(r *http.Request, stream *Stream) error {
+		return fmt.Errorf("dial tcp 10.0.0.1:3306: connection refused")
+	}, WithHeartbeat(0))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/options", nil))
+
+	assert.Equal(t, "event: error\ndata: {\"result\":false,\"code\":50000,\"message\":\"internal error\",\"data\":null}\n\n", rec.Body.String())
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	handler := SSE(func(r *http.Request, stream *Stream) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, WithHeartbeat(5*time.Millisecond))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/options", nil))
+
+	assert.True(t, strings.HasPrefix(rec.Body.String(), ": heartbeat\n\n"))
+	assert.NotContains(t, rec.Body.String(), "data:")
+}
+
+func TestSSEClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := SSE(func(r *http.Request, stream *Stream) error {
+		assert.NoError(t, stream.Send(Event{Data: "first"}))
+		cancel()
+		<-stream.Context().Done()
+		return stream.Send(Event{Data: "second"})
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/options", nil).WithContext(ctx))
+
+	assert.Equal(t, "data: first\n\n", rec.Body.String())
+}
+
+func TestSSEWithoutFlusher(t *testing.T) {
+	called := false
+	handler := SSE(func(r *http.Request, stream *Stream) error {
+		called = true
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(nonFlushingWriter{rec}, httptest.NewRequest(http.MethodGet, "/options", nil))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"result":false,"code":50000,"message":"streaming is not supported by response writer","data":null}`, rec.Body.String())
+}
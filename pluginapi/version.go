@@ -0,0 +1,72 @@
+package pluginapi
+
+import "github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+
+type versionRegistrar struct {
+	version   string
+	registrar Registrar
+}
+
+func (r versionRegistrar) mounted() Registrar {
+	return func(router Router) {
+		router.Group(VersionPrefix(r.version), r.registrar)
+	}
+}
+
+// VersionPrefix returns the path prefix which plugin APIs registered for
+// version are mounted under.
+func VersionPrefix(version string) string {
+	return "/versions/" + version
+}
+
+// RegisterForVersion stores a plugin API registrar scoped to a plugin version,
+// routes it registers are mounted under VersionPrefix(version) and listed in
+// the detail of the version.
+func RegisterForVersion(version string, registrar Registrar) {
+	mu.Lock()
+	defer mu.Unlock()
+	versionRegistrars = append(versionRegistrars, versionRegistrar{version: version, registrar: registrar})
+}
+
+// VersionRoutes returns the routes registered for version by
+// RegisterForVersion, their paths include the version prefix.
+func VersionRoutes(version string) []Route {
+	mu.RLock()
+	var scoped []Registrar
+	for _, r := range versionRegistrars {
+		if r.version == version {
+			scoped = append(scoped, r.mounted())
+		}
+	}
+	mu.RUnlock()
+
+	if len(scoped) == 0 {
+		return nil
+	}
+	return CollectRoutes(scoped)
+}
+
+// DetailAPIs returns the plugin APIs registered for version by
+// RegisterForVersion, which are listed in the detail payload of the version.
+func DetailAPIs(version string) []protocol.DetailAPI {
+	var apis []protocol.DetailAPI
+	for _, route := range VersionRoutes(version) {
+		apis = append(apis, protocol.DetailAPI{
+			Method:  route.Method,
+			Path:    route.Path,
+			Summary: route.Operation.Summary,
+		})
+	}
+	return apis
+}
+
+// DetailOptions returns a copy of opts with the plugin APIs of version and
+// a resolver of form remote options like ResolveRoute, which should be passed
+// to protocol.BuildDetail by runtimes serving plugin APIs. The resolver
+// collects the routes once, so the options should not be reused after plugin
+// APIs are registered.
+func DetailOptions(version string, opts protocol.DetailOptions) protocol.DetailOptions {
+	opts.APIs = DetailAPIs(version)
+	opts.ResolvePluginAPI = newRouteResolver()
+	return opts
+}
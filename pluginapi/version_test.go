@@ -0,0 +1,61 @@
+package pluginapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/protocol"
+)
+
+type versionTestPlugin struct {
+	version string
+}
+
+func (p versionTestPlugin) Version() string            { return p.version }
+func (p versionTestPlugin) Desc() string               { return "version plugin" }
+func (p versionTestPlugin) Execute(*kit.Context) error { return nil }
+
+func TestRegisterForVersion(t *testing.T) {
+	ResetForTest()
+	t.Cleanup(ResetForTest)
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	Register(func(router Router) {
+		router.GET("/health", noop)
+	})
+	RegisterForVersion("14.0.0", func(router Router) {
+		router.GET("/hosts", noop)
+		Describe(router, http.MethodGet, "/hosts", Operation{Summary: "List hosts"})
+	})
+	RegisterForVersion("14.0.1", func(router Router) {
+		router.GET("/hosts/:id", noop)
+	})
+
+	routes := Routes()
+	require.Len(t, routes, 3)
+	assert.Equal(t, "/health", routes[0].Path)
+	assert.Equal(t, "/versions/14.0.0/hosts", routes[1].Path)
+	assert.Equal(t, "/versions/14.0.1/hosts/:id", routes[2].Path)
+
+	versionRoutes := VersionRoutes("14.0.0")
+	require.Len(t, versionRoutes, 1)
+	assert.Equal(t, "/versions/14.0.0/hosts", versionRoutes[0].Path)
+	assert.Equal(t, "List hosts", versionRoutes[0].Operation.Summary)
+	assert.Nil(t, VersionRoutes("14.0.2"))
+
+	hub.MustInstallV2(versionTestPlugin{version: "14.0.0"}, hub.PluginSpec{})
+	data, err := protocol.BuildDetail("14.0.0", DetailOptions("14.0.0", protocol.DetailOptions{EnablePluginCallback: true}))
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.DetailAPI{{Method: "GET", Path: "/versions/14.0.0/hosts", Summary: "List hosts"}}, data.APIs)
+	assert.True(t, data.EnablePluginCallback)
+
+	// importing pluginapi does not change the payload built without its options
+	data, err = protocol.BuildDetail("14.0.0", protocol.DetailOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, data.APIs)
+}
@@ -0,0 +1,308 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package pluginvet provides analyzers reporting common mistakes in the usage
+// of kit.Plugin and kit.Context, which can be run by cmd/bkpluginvet or go vet
+// with -vettool.
+package pluginvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const (
+	kitPath       = "github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+	constantsPath = "github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+)
+
+// versionRe is the plugin version format checked by hub.ValidVersion, it is
+// copied so that pluginvet does not depend on the framework module.
+var versionRe = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9][a-z0-9]*$`)
+
+// Analyzers are all analyzers of the package.
+var Analyzers = []*analysis.Analyzer{WaitConflict, CallbackState, VersionFormat, ReadPointer}
+
+// WaitConflict reports functions which may call both WaitPoll and
+// WaitCallback of a kit.Context in one execution.
+var WaitConflict = &analysis.Analyzer{
+	Name:     "waitconflict",
+	Doc:      "report calling both WaitPoll and WaitCallback of kit.Context in one execution",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runWaitConflict,
+}
+
+// CallbackState reports reading callback data without checking that the
+// state of kit.Context is constants.StateCallback.
+var CallbackState = &analysis.Analyzer{
+	Name:     "callbackstate",
+	Doc:      "report calling ReadCallback of kit.Context without checking its state",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runCallbackState,
+}
+
+// VersionFormat reports Version methods of plugins returning constants which
+// are not valid plugin versions, which make hub.MustInstall panic.
+var VersionFormat = &analysis.Analyzer{
+	Name:     "versionformat",
+	Doc:      "report plugin Version methods returning invalid versions",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runVersionFormat,
+}
+
+// ReadPointer reports non-pointer values passed to the read methods of
+// kit.Context, which can not store the data read.
+var ReadPointer = &analysis.Analyzer{
+	Name:     "readpointer",
+	Doc:      "report non-pointer arguments of kit.Context read methods",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runReadPointer,
+}
+
+// contextMethod returns the name of the kit.Context method called by call, or
+// empty if call does not call a method of kit.Context.
+func contextMethod(info *types.Info, call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return ""
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil || !isContext(recv.Type()) {
+		return ""
+	}
+	return fn.Name()
+}
+
+// isContext reports whether t is kit.Context or a pointer to it.
+func isContext(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == kitPath && obj.Name() == "Context"
+}
+
+// funcBodies calls fn with the body of each function declaration and literal
+// of the pass, bodies of nested function literals are visited separately.
+func funcBodies(pass *analysis.Pass, fn func(body *ast.BlockStmt)) {
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				fn(n.Body)
+			}
+		case *ast.FuncLit:
+			fn(n.Body)
+		}
+	})
+}
+
+// inspectBody calls fn with the nodes of body and their ancestors under body,
+// skipping nested function literals.
+func inspectBody(body *ast.BlockStmt, fn func(n ast.Node, stack []ast.Node)) {
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		fn(n, stack)
+		stack = append(stack, n)
+		return true
+	})
+}
+
+type waitCall struct {
+	call  *ast.CallExpr
+	name  string
+	stack []ast.Node
+}
+
+func runWaitConflict(pass *analysis.Pass) (interface{}, error) {
+	funcBodies(pass, func(body *ast.BlockStmt) {
+		var calls []waitCall
+		inspectBody(body, func(n ast.Node, stack []ast.Node) {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return
+			}
+			if name := contextMethod(pass.TypesInfo, call); name == "WaitPoll" || name == "WaitCallback" {
+				calls = append(calls, waitCall{call: call, name: name, stack: append([]ast.Node(nil), stack...)})
+			}
+		})
+
+		for i, later := range calls {
+			for _, earlier := range calls[:i] {
+				if earlier.name != later.name && !exclusive(earlier.stack, later.stack) {
+					pass.Reportf(later.call.Pos(), "%s is called after %s in the same execution, a plugin can wait for either poll or callback", later.name, earlier.name)
+					break
+				}
+			}
+		}
+	})
+	return nil, nil
+}
+
+// exclusive reports whether the nodes with ancestors a and b are in different
+// branches of an if, switch or select statement.
+func exclusive(a []ast.Node, b []ast.Node) bool {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	if i == 0 || i == len(a) || i == len(b) {
+		return false
+	}
+	switch common := a[i-1].(type) {
+	case *ast.IfStmt:
+		return a[i] != common.Cond && b[i] != common.Cond && a[i] != common.Init && b[i] != common.Init
+	case *ast.BlockStmt:
+		_, aCase := a[i].(*ast.CaseClause)
+		_, bCase := b[i].(*ast.CaseClause)
+		_, aComm := a[i].(*ast.CommClause)
+		_, bComm := b[i].(*ast.CommClause)
+		return (aCase && bCase) || (aComm && bComm)
+	}
+	return false
+}
+
+func runCallbackState(pass *analysis.Pass) (interface{}, error) {
+	funcBodies(pass, func(body *ast.BlockStmt) {
+		checked := token.NoPos
+		inspectBody(body, func(n ast.Node, stack []ast.Node) {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				switch contextMethod(pass.TypesInfo, n) {
+				case "State":
+					if checked == token.NoPos {
+						checked = n.Pos()
+					}
+				case "ReadCallback":
+					if checked == token.NoPos {
+						pass.Reportf(n.Pos(), "ReadCallback is called without checking that State is constants.StateCallback")
+					}
+				}
+			case *ast.SelectorExpr:
+				// the state may be checked through a variable or a parameter
+				if obj, ok := pass.TypesInfo.Uses[n.Sel].(*types.Const); ok && checked == token.NoPos &&
+					obj.Pkg() != nil && obj.Pkg().Path() == constantsPath && obj.Name() == "StateCallback" {
+					checked = n.Pos()
+				}
+			}
+		})
+	})
+	return nil, nil
+}
+
+func runVersionFormat(pass *analysis.Pass) (interface{}, error) {
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		decl := n.(*ast.FuncDecl)
+		if decl.Recv == nil || decl.Name.Name != "Version" || decl.Body == nil {
+			return
+		}
+		fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+		if !ok || !isPlugin(fn.Type().(*types.Signature).Recv().Type()) {
+			return
+		}
+
+		inspectBody(decl.Body, func(n ast.Node, stack []ast.Node) {
+			ret, ok := n.(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				return
+			}
+			value := pass.TypesInfo.Types[ret.Results[0]].Value
+			if value == nil || value.Kind() != constant.String {
+				return
+			}
+			if version := constant.StringVal(value); !versionRe.MatchString(version) {
+				pass.Reportf(ret.Results[0].Pos(), "%q is not a valid plugin version, hub.MustInstall will panic", version)
+			}
+		})
+	})
+	return nil, nil
+}
+
+// isPlugin reports whether the method set of t or *t has the methods of
+// kit.Plugin.
+func isPlugin(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	mset := types.NewMethodSet(types.NewPointer(t))
+	for _, name := range []string{"Version", "Desc"} {
+		sel := mset.Lookup(nil, name)
+		if sel == nil {
+			return false
+		}
+		sig := sel.Type().(*types.Signature)
+		if sig.Params().Len() != 0 || sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), types.Typ[types.String]) {
+			return false
+		}
+	}
+	sel := mset.Lookup(nil, "Execute")
+	if sel == nil {
+		return false
+	}
+	sig := sel.Type().(*types.Signature)
+	return sig.Params().Len() == 1 && isContext(sig.Params().At(0).Type())
+}
+
+// readMethods are the kit.Context methods decoding data into their argument.
+var readMethods = map[string]bool{
+	"ReadInputs":        true,
+	"ReadContextInputs": true,
+	"ReadCallback":      true,
+	"Read":              true,
+	"ReadOutputs":       true,
+}
+
+func runReadPointer(pass *analysis.Pass) (interface{}, error) {
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		name := contextMethod(pass.TypesInfo, call)
+		if !readMethods[name] || len(call.Args) != 1 {
+			return
+		}
+		t := pass.TypesInfo.TypeOf(call.Args[0])
+		if t == nil {
+			return
+		}
+		switch t.Underlying().(type) {
+		case *types.Pointer, *types.Interface:
+			return
+		}
+		if basic, ok := t.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+			pass.Reportf(call.Args[0].Pos(), "%s is called with nil, pass a pointer to store the data", name)
+			return
+		}
+		pass.Reportf(call.Args[0].Pos(), "%s is called with non-pointer %s, pass a pointer to store the data", name, types.TypeString(t, types.RelativeTo(pass.Pkg)))
+	})
+	return nil, nil
+}
@@ -0,0 +1,7 @@
+// Package constants is a stub of the framework constants package, which
+// declares only what the pluginvet tests use.
+package constants
+
+type State int8
+
+const StateCallback State = 3
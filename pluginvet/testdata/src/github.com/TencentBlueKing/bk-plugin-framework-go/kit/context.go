@@ -0,0 +1,20 @@
+// Package kit is a stub of the framework kit package, which declares only
+// the kit.Context methods checked by the pluginvet analyzers.
+package kit
+
+import (
+	"time"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+)
+
+type Context struct{}
+
+func (c *Context) State() constants.State                   { return 0 }
+func (c *Context) WaitPoll(interval time.Duration) error    { return nil }
+func (c *Context) WaitCallback(timeout time.Duration) error { return nil }
+func (c *Context) ReadInputs(v interface{}) error           { return nil }
+func (c *Context) ReadContextInputs(v interface{}) error    { return nil }
+func (c *Context) ReadCallback(v interface{}) error         { return nil }
+func (c *Context) Read(v interface{}) error                 { return nil }
+func (c *Context) ReadOutputs(v interface{}) error          { return nil }
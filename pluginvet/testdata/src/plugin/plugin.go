@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+type Inputs struct {
+	Mode string `json:"mode"`
+}
+
+type Plugin struct{}
+
+func (p *Plugin) Version() string {
+	return "v1.0" // want `"v1.0" is not a valid plugin version, hub.MustInstall will panic`
+}
+
+func (p *Plugin) Desc() string {
+	return "plugin"
+}
+
+func (p *Plugin) Execute(c *kit.Context) error {
+	var inputs Inputs
+	if err := c.ReadInputs(inputs); err != nil { // want `ReadInputs is called with non-pointer Inputs, pass a pointer to store the data`
+		return err
+	}
+	if err := c.ReadContextInputs(nil); err != nil { // want `ReadContextInputs is called with nil, pass a pointer to store the data`
+		return err
+	}
+	var data map[string]interface{}
+	if err := c.ReadCallback(&data); err != nil { // want `ReadCallback is called without checking that State is constants.StateCallback`
+		return err
+	}
+
+	c.WaitPoll(time.Second)
+	c.WaitCallback(time.Minute) // want `WaitCallback is called after WaitPoll in the same execution, a plugin can wait for either poll or callback`
+	return nil
+}
+
+type ValidPlugin struct{}
+
+const version = "1.0.0rc1"
+
+func (p ValidPlugin) Version() string {
+	return version
+}
+
+func (p ValidPlugin) Desc() string {
+	return "plugin"
+}
+
+func (p ValidPlugin) Execute(c *kit.Context) error {
+	var inputs Inputs
+	if err := c.ReadInputs(&inputs); err != nil {
+		return err
+	}
+	var v interface{} = &inputs
+	if err := c.Read(v); err != nil {
+		return err
+	}
+
+	switch c.State() {
+	case constants.StateCallback:
+		var data map[string]interface{}
+		return c.ReadCallback(&data)
+	}
+
+	if inputs.Mode == "poll" {
+		c.WaitPoll(time.Second)
+	} else {
+		c.WaitCallback(time.Minute)
+	}
+
+	switch inputs.Mode {
+	case "poll":
+		c.WaitPoll(time.Second)
+	case "callback":
+		c.WaitCallback(time.Minute)
+	}
+	return nil
+}
+
+func readCallback(c *kit.Context, state constants.State) error {
+	if state != constants.StateCallback {
+		return nil
+	}
+	var data map[string]interface{}
+	return c.ReadCallback(&data)
+}
+
+type notPlugin struct{}
+
+func (n notPlugin) Version() string {
+	return "latest"
+}
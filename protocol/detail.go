@@ -9,12 +9,39 @@
 
 package protocol
 
-import "github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+import (
+	"fmt"
+	"sort"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
+	"github.com/TencentBlueKing/bk-plugin-framework-go/kit"
+)
+
+// DefaultPluginAPIPrefix is the path prefix under which runtime serves plugin APIs.
+const DefaultPluginAPIPrefix = "/bk_plugin/plugin_api"
 
 // DetailOptions stores runtime-provided flags for the plugin service detail API.
+//
+// TenantID is the tenant requesting the detail, the EnablePluginCallback
+// override of the tenant in hub options takes precedence over
+// EnablePluginCallback.
+//
+// PluginAPIPrefix is the path prefix of plugin APIs used by form remote
+// options, DefaultPluginAPIPrefix is used if it is empty.
+//
+// APIs lists the plugin APIs scoped to the version, see pluginapi.DetailAPIs.
+//
+// ResolvePluginAPI resolves the path of a plugin API referred by form remote
+// options of a version to its registered full path, the bool result reports
+// whether the API is registered, see pluginapi.ResolveRoute. Paths are used
+// as is if it is nil.
 type DetailOptions struct {
+	TenantID             string
 	EnablePluginCallback bool
 	RenderForm           interface{}
+	PluginAPIPrefix      string
+	APIs                 []DetailAPI
+	ResolvePluginAPI     func(version string, method string, path string) (string, bool)
 }
 
 // DetailForms stores form render metadata.
@@ -22,6 +49,13 @@ type DetailForms struct {
 	RenderForm interface{} `json:"renderform"`
 }
 
+// DetailAPI describes a plugin API scoped to a plugin version.
+type DetailAPI struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Summary string `json:"summary,omitempty"`
+}
+
 // DetailData is the data payload returned by the plugin service detail API.
 type DetailData struct {
 	Version              string                 `json:"version"`
@@ -31,6 +65,7 @@ type DetailData struct {
 	ContextInputs        map[string]interface{} `json:"context_inputs"`
 	Outputs              map[string]interface{} `json:"outputs"`
 	Forms                DetailForms            `json:"forms"`
+	APIs                 []DetailAPI            `json:"apis,omitempty"`
 }
 
 // BuildDetail builds the standard plugin service detail payload.
@@ -45,15 +80,115 @@ func BuildDetail(version string, opts DetailOptions) (DetailData, error) {
 		renderForm = detail.FormsRenderFormJSON()
 	}
 
+	if form, ok := renderForm.(map[string]interface{}); ok {
+		prefix := opts.PluginAPIPrefix
+		if prefix == "" {
+			prefix = DefaultPluginAPIPrefix
+		}
+		renderForm, err = renderRemoteOptions(detail.Plugin().Version(), form, prefix, opts.ResolvePluginAPI)
+		if err != nil {
+			return DetailData{}, err
+		}
+	}
+
+	enableCallback := opts.EnablePluginCallback
+	if opts.TenantID != "" {
+		if override := hub.GetOptions().Tenants[opts.TenantID].EnablePluginCallback; override != nil {
+			enableCallback = *override
+		}
+	}
+
 	return DetailData{
 		Version:              detail.Plugin().Version(),
 		Desc:                 detail.Plugin().Desc(),
-		EnablePluginCallback: opts.EnablePluginCallback,
+		EnablePluginCallback: enableCallback,
 		Inputs:               detail.InputsSchemaJSON(),
 		ContextInputs:        detail.ContextInputsSchemaJSON(),
 		Outputs:              detail.OutputsSchemaJSON(),
 		Forms: DetailForms{
 			RenderForm: renderForm,
 		},
+		APIs: opts.APIs,
 	}, nil
 }
+
+// renderRemoteOptions returns a copy of form whose remote options sources are
+// replaced by renderform remote config of select component.
+func renderRemoteOptions(
+	version string,
+	form map[string]interface{},
+	prefix string,
+	resolve func(version string, method string, path string) (string, bool),
+) (map[string]interface{}, error) {
+	fields := make([]string, 0, len(form))
+	for field := range form {
+		fields = append(fields, field)
+	}
+	// check fields in order so the error is stable
+	sort.Strings(fields)
+
+	rendered := make(map[string]interface{}, len(form))
+	for _, field := range fields {
+		attrs := form[field]
+		rendered[field] = attrs
+		attrsJSON, ok := attrs.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		source, found, err := kit.RemoteOptionsOf(attrsJSON)
+		if err != nil {
+			return nil, fmt.Errorf("form field %s: %v", field, err)
+		}
+		if !found {
+			continue
+		}
+
+		path := source.Path
+		if resolve != nil {
+			resolved, ok := resolve(version, source.Method, source.Path)
+			if !ok {
+				return nil, fmt.Errorf("form field %s: remote options refer to unregistered plugin api %s %s", field, source.Method, source.Path)
+			}
+			path = resolved
+		}
+
+		remoteConfig := map[string]interface{}{
+			"url":    prefix + path,
+			"method": source.Method,
+			"responseParse": map[string]interface{}{
+				"dataKey":  "data",
+				"labelKey": source.LabelKey,
+				"valueKey": source.ValueKey,
+			},
+		}
+		if len(source.Params) > 0 {
+			remoteConfig["params"] = source.Params
+		}
+
+		copied := make(map[string]interface{}, len(attrsJSON))
+		for k, v := range attrsJSON {
+			if k != kit.RemoteOptionsKey {
+				copied[k] = v
+			}
+		}
+		// keep the props set by the form, e.g. placeholder and multiple
+		component := map[string]interface{}{}
+		props := map[string]interface{}{}
+		if existing, ok := attrsJSON["ui:component"].(map[string]interface{}); ok {
+			for k, v := range existing {
+				component[k] = v
+			}
+			if existingProps, ok := existing["props"].(map[string]interface{}); ok {
+				for k, v := range existingProps {
+					props[k] = v
+				}
+			}
+		}
+		props["remoteConfig"] = remoteConfig
+		component["name"] = kit.ComponentSelect
+		component["props"] = props
+		copied["ui:component"] = component
+		rendered[field] = copied
+	}
+	return rendered, nil
+}
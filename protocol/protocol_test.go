@@ -104,6 +104,22 @@ func TestBuildDetailIncludesExplicitRenderForm(t *testing.T) {
 	require.Contains(t, string(raw), `"renderform":{"mode":{"component":"input"}}`)
 }
 
+func TestBuildDetailUsesTenantCallbackOption(t *testing.T) {
+	version := nextProtocolTestVersion()
+	hub.MustInstallV2(protocolTestPlugin{version: version, desc: "tenant plugin"}, hub.PluginSpec{})
+	disabled := false
+	hub.Configure(hub.Options{Tenants: map[string]hub.TenantOptions{"tenant-a": {EnablePluginCallback: &disabled}}})
+	defer hub.Configure(hub.Options{})
+
+	data, err := BuildDetail(version, DetailOptions{TenantID: "tenant-a", EnablePluginCallback: true})
+	require.NoError(t, err)
+	require.False(t, data.EnablePluginCallback)
+
+	data, err = BuildDetail(version, DetailOptions{TenantID: "tenant-b", EnablePluginCallback: true})
+	require.NoError(t, err)
+	require.True(t, data.EnablePluginCallback)
+}
+
 func TestBuildDetailKeepsLegacyInputsFormAsInputs(t *testing.T) {
 	version := nextProtocolTestVersion()
 	hub.MustInstall(protocolTestPlugin{version: version, desc: "legacy plugin"}, nil, nil, []byte(`{
@@ -128,3 +144,83 @@ func TestBuildDetailReturnsMissingVersionError(t *testing.T) {
 	require.Error(t, err)
 	require.Empty(t, data.Version)
 }
+
+func TestBuildDetailListsVersionAPIs(t *testing.T) {
+	version := nextProtocolTestVersion()
+	hub.MustInstallV2(protocolTestPlugin{version: version, desc: "api plugin"}, hub.PluginSpec{})
+
+	apis := []DetailAPI{{Method: "GET", Path: "/versions/" + version + "/hosts", Summary: "List hosts"}}
+	data, err := BuildDetail(version, DetailOptions{APIs: apis})
+	require.NoError(t, err)
+	require.Equal(t, apis, data.APIs)
+
+	data, err = BuildDetail(version, DetailOptions{})
+	require.NoError(t, err)
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), `"apis"`)
+}
+
+func TestBuildDetailRendersRemoteOptions(t *testing.T) {
+	version := nextProtocolTestVersion()
+	form, err := json.Marshal(kit.Form{
+		"host":   kit.RemoteOptions("/hosts", kit.WithRemoteKeys("ip", "id"), kit.WithRemoteParams(map[string]interface{}{"limit": 10})),
+		"name":   {"title": "Name"},
+		"module": kit.NewForm().Field("module").Props(kit.F{"placeholder": "Module"}).Remote("/hosts").Form()["module"],
+	})
+	require.NoError(t, err)
+	hub.MustInstallV2(protocolTestPlugin{version: version, desc: "remote plugin"}, hub.PluginSpec{Form: form})
+
+	resolve := func(v string, method string, path string) (string, bool) {
+		return "/versions/" + v + path, method == "GET" && path == "/hosts"
+	}
+
+	data, err := BuildDetail(version, DetailOptions{PluginAPIPrefix: "/prefix", ResolvePluginAPI: resolve})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"host": map[string]interface{}{
+			"ui:component": map[string]interface{}{
+				"name": "bk-select",
+				"props": map[string]interface{}{
+					"remoteConfig": map[string]interface{}{
+						"url":    "/prefix/versions/" + version + "/hosts",
+						"method": "GET",
+						"params": map[string]interface{}{"limit": float64(10)},
+						"responseParse": map[string]interface{}{
+							"dataKey":  "data",
+							"labelKey": "ip",
+							"valueKey": "id",
+						},
+					},
+				},
+			},
+		},
+		"name": map[string]interface{}{"title": "Name"},
+		"module": map[string]interface{}{
+			"ui:component": map[string]interface{}{
+				"name": "bk-select",
+				"props": map[string]interface{}{
+					"placeholder": "Module",
+					"remoteConfig": map[string]interface{}{
+						"url":    "/prefix/versions/" + version + "/hosts",
+						"method": "GET",
+						"responseParse": map[string]interface{}{
+							"dataKey":  "data",
+							"labelKey": "label",
+							"valueKey": "value",
+						},
+					},
+				},
+			},
+		},
+	}, data.Forms.RenderForm)
+
+	// the installed form is not modified
+	detail, err := hub.GetPluginDetail(version)
+	require.NoError(t, err)
+	require.Contains(t, detail.FormsRenderFormJSON()["host"], kit.RemoteOptionsKey)
+
+	unresolved := func(string, string, string) (string, bool) { return "", false }
+	_, err = BuildDetail(version, DetailOptions{ResolvePluginAPI: unresolved})
+	require.EqualError(t, err, "form field host: remote options refer to unregistered plugin api GET /hosts")
+}
@@ -10,7 +10,11 @@
 // Package runtime define the plugin runtime related interfaces.
 package runtime
 
-import "time"
+import (
+	"time"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+)
 
 // CallbackPreparation contains the callback endpoint prepared before a plugin
 // enters StateCallback.
@@ -38,6 +42,25 @@ type CallbackReader interface {
 	ReadCallback(v interface{}) error
 }
 
+// InvokeScope describes which app invokes a plugin and the business scope
+// the invocation belongs to, e.g. a Standard Ops project.
+type InvokeScope struct {
+	TenantID   string `json:"tenant_id"`
+	CallerApp  string `json:"caller_app"`
+	ScopeType  string `json:"scope_type"`
+	ScopeValue string `json:"scope_value"`
+}
+
+// ScopeReader is an optional interface implemented by readers that know the
+// invoke scope of current execution. Executor checks the scope against hub
+// allow scope before executing the plugin when the reader implements it.
+// TenantID and CallerApp of the scope are only used when the authenticated
+// caller of the execution has none, a scope claiming other values is
+// rejected.
+type ScopeReader interface {
+	ReadScope() (InvokeScope, error)
+}
+
 // ObjectStore is the interface that wraps the basic store operate method.
 //
 // # Write should store the value pointed to by v with traceID
@@ -49,6 +72,32 @@ type ObjectStore interface {
 	Read(traceID string, v interface{}) error
 }
 
+// TenantObjectStore is an optional interface implemented by ObjectStore which
+// isolates stored data by tenant. Context uses it instead of Write and Read
+// when the caller of the execution has a tenant id.
+//
+// Runtimes using such stores should implement PluginTenantRuntime, otherwise
+// executions of a tenant must be resumed with the caller, e.g. by
+// executor.ScheduleWithCaller, to read the data written in execute phase.
+type TenantObjectStore interface {
+	WriteTenant(tenantID string, traceID string, v interface{}) error
+	ReadTenant(tenantID string, traceID string, v interface{}) error
+}
+
+// PluginTenantRuntime is an optional interface implemented by runtimes that
+// persist the tenant of executions.
+//
+// SetTenant should persist tenantID with the execution of traceID, it is
+// called in execute phase when the caller of the execution has a tenant id.
+//
+// GetTenant should return the tenant id persisted with the execution of
+// traceID, or an empty string if there is none. The schedule, retry and
+// cancel actions use it when their caller has no tenant id.
+type PluginTenantRuntime interface {
+	SetTenant(traceID string, tenantID string) error
+	GetTenant(traceID string) (string, error)
+}
+
 // PluginExecuteRuntime is the interface that wraps the basic runtime method
 // used in plugin execute phase.
 //
@@ -88,3 +137,24 @@ type PluginScheduleExecuteRuntime interface {
 	SetFail(traceID string, err error) error
 	SetSuccess(traceID string) error
 }
+
+// PluginCancelRuntime is an optional interface implemented by runtimes that
+// support cancelling executions waiting for poll or callback.
+//
+// SetCancelled should mark execution with traceID as StateCancelled, after
+// that, runtime should not execute the plugin of this execution any more.
+type PluginCancelRuntime interface {
+	SetCancelled(traceID string) error
+}
+
+// PluginRetryRuntime is an optional interface implemented by runtimes that
+// support manual retry of failed executions.
+//
+// SetRetry should mark the failed execution with traceID as resumed in state
+// with invokeCount. The executor only checks state by statemachine.CheckRetry,
+// so SetRetry must return an error if the execution is not in
+// constants.StateFail. After that, the execution is scheduled again with its
+// last persisted context.
+type PluginRetryRuntime interface {
+	SetRetry(traceID string, version string, invokeCount int, state constants.State) error
+}
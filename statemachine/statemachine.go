@@ -0,0 +1,103 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package statemachine define the legal state transitions of a bk-plugin
+// execution.
+//
+// An execution starts in constants.StateEmpty, every invocation of the plugin
+// moves it to a waiting state, constants.StatePoll or constants.StateCallback,
+// or to a terminal state, constants.StateSuccess or constants.StateFail. Only
+// executions in a waiting state can be scheduled again or cancelled, which
+// moves them to the terminal state constants.StateCancelled. A failed
+// execution can be retried manually, which resumes it in constants.StatePoll.
+package statemachine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+)
+
+// ErrIllegalTransition is wrapped by errors of illegal transitions and
+// resume states.
+var ErrIllegalTransition = errors.New("illegal state transition")
+
+var transitions = map[constants.State][]constants.State{
+	constants.StateEmpty:     {constants.StatePoll, constants.StateCallback, constants.StateSuccess, constants.StateFail},
+	constants.StatePoll:      {constants.StatePoll, constants.StateCallback, constants.StateSuccess, constants.StateFail, constants.StateCancelled},
+	constants.StateCallback:  {constants.StatePoll, constants.StateCallback, constants.StateSuccess, constants.StateFail, constants.StateCancelled},
+	constants.StateSuccess:   nil,
+	constants.StateFail:      {constants.StatePoll}, // manual retry from the last poll step
+	constants.StateCancelled: nil,
+}
+
+// Next returns the states which from can transit to, it returns nil for
+// unknown states and terminal states except constants.StateFail, which can
+// be retried manually.
+func Next(from constants.State) []constants.State {
+	return append([]constants.State(nil), transitions[from]...)
+}
+
+// CanTransit reports whether an execution in state from can transit to state
+// to.
+func CanTransit(from constants.State, to constants.State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transit returns an error wrapping ErrIllegalTransition if from can not
+// transit to to.
+func Transit(from constants.State, to constants.State) error {
+	if !CanTransit(from, to) {
+		return fmt.Errorf("%w from %s to %s", ErrIllegalTransition, from, to)
+	}
+	return nil
+}
+
+// IsTerminal reports whether s is a terminal state, in which the execution
+// is not scheduled any more unless a failed execution is retried manually.
+func IsTerminal(s constants.State) bool {
+	return s == constants.StateSuccess || s == constants.StateFail || s == constants.StateCancelled
+}
+
+// IsWaiting reports whether s is a waiting state, in which the execution is
+// scheduled again.
+func IsWaiting(s constants.State) bool {
+	return s == constants.StatePoll || s == constants.StateCallback
+}
+
+// CheckResume returns an error wrapping ErrIllegalTransition if an execution
+// can not be scheduled in state s.
+func CheckResume(s constants.State) error {
+	if !IsWaiting(s) {
+		return fmt.Errorf("%w: can not resume execution in state %s, expect %s or %s",
+			ErrIllegalTransition, s, constants.StatePoll, constants.StateCallback)
+	}
+	return nil
+}
+
+// CheckRetry returns an error wrapping ErrIllegalTransition if a failed
+// execution can not be retried manually in state to. Retried executions are
+// resumed in constants.StatePoll only, because the callback payload of a
+// failed constants.StateCallback invocation is not available any more.
+//
+// CheckRetry does not know the current state of the execution, which should be
+// checked to be constants.StateFail by the runtime.
+func CheckRetry(to constants.State) error {
+	if !CanTransit(constants.StateFail, to) {
+		return fmt.Errorf("%w: can not retry execution in state %s, expect %s",
+			ErrIllegalTransition, to, constants.StatePoll)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package statemachine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/constants"
+)
+
+func TestCanTransit(t *testing.T) {
+	var cases = []struct {
+		from     constants.State
+		to       constants.State
+		expected bool
+	}{
+		{constants.StateEmpty, constants.StatePoll, true},
+		{constants.StateEmpty, constants.StateSuccess, true},
+		{constants.StateEmpty, constants.StateEmpty, false},
+		{constants.StatePoll, constants.StatePoll, true},
+		{constants.StatePoll, constants.StateCallback, true},
+		{constants.StateCallback, constants.StateSuccess, true},
+		{constants.StateCallback, constants.StateFail, true},
+		{constants.StatePoll, constants.StateEmpty, false},
+		{constants.StateSuccess, constants.StatePoll, false},
+		{constants.StateFail, constants.StateFail, false},
+		{constants.StateFail, constants.StatePoll, true},
+		{constants.StateFail, constants.StateCallback, false},
+		{constants.StatePoll, constants.StateCancelled, true},
+		{constants.StateCallback, constants.StateCancelled, true},
+		{constants.StateEmpty, constants.StateCancelled, false},
+		{constants.StateCancelled, constants.StatePoll, false},
+		{constants.State(0), constants.StatePoll, false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, CanTransit(c.from, c.to), "%s -> %s", c.from, c.to)
+	}
+}
+
+func TestTransit(t *testing.T) {
+	assert.NoError(t, Transit(constants.StatePoll, constants.StateSuccess))
+
+	err := Transit(constants.StateSuccess, constants.StatePoll)
+	assert.EqualError(t, err, "illegal state transition from success to poll")
+	assert.True(t, errors.Is(err, ErrIllegalTransition))
+}
+
+func TestNext(t *testing.T) {
+	next := Next(constants.StateEmpty)
+	assert.Equal(t, []constants.State{constants.StatePoll, constants.StateCallback, constants.StateSuccess, constants.StateFail}, next)
+	next[0] = constants.StateFail
+	assert.Equal(t, constants.StatePoll, Next(constants.StateEmpty)[0])
+	assert.Nil(t, Next(constants.StateSuccess))
+}
+
+func TestStateKinds(t *testing.T) {
+	assert.True(t, IsTerminal(constants.StateSuccess))
+	assert.True(t, IsTerminal(constants.StateFail))
+	assert.True(t, IsTerminal(constants.StateCancelled))
+	assert.False(t, IsTerminal(constants.StatePoll))
+	assert.False(t, IsTerminal(constants.State(0)))
+
+	assert.True(t, IsWaiting(constants.StatePoll))
+	assert.True(t, IsWaiting(constants.StateCallback))
+	assert.False(t, IsWaiting(constants.StateEmpty))
+	assert.False(t, IsWaiting(constants.StateSuccess))
+}
+
+func TestCheckResume(t *testing.T) {
+	assert.NoError(t, CheckResume(constants.StatePoll))
+	assert.NoError(t, CheckResume(constants.StateCallback))
+	assert.Error(t, CheckResume(constants.StateCancelled))
+
+	err := CheckResume(constants.StateSuccess)
+	assert.EqualError(t, err, "illegal state transition: can not resume execution in state success, expect poll or callback")
+	assert.True(t, errors.Is(err, ErrIllegalTransition))
+	assert.EqualError(t, CheckResume(constants.State(9)), "illegal state transition: can not resume execution in state State(9), expect poll or callback")
+}
+
+func TestCheckRetry(t *testing.T) {
+	assert.NoError(t, CheckRetry(constants.StatePoll))
+	assert.EqualError(t, CheckRetry(constants.StateCallback),
+		"illegal state transition: can not retry execution in state callback, expect poll")
+	assert.EqualError(t, CheckRetry(constants.StateEmpty),
+		"illegal state transition: can not retry execution in state empty, expect poll")
+	assert.True(t, errors.Is(CheckRetry(constants.StateSuccess), ErrIllegalTransition))
+}
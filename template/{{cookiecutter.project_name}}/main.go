@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/TencentBlueKing/bk-plugin-framework-go/cli"
 	"github.com/TencentBlueKing/bk-plugin-framework-go/hub"
 	"github.com/TencentBlueKing/bk-plugin-runtime-go/runner"
 	v100 "{{cookiecutter.project_name}}/versions/v100"
@@ -13,5 +14,6 @@ func main() {
 		Outputs:       v100.Outputs{},
 		Form:          v100.InputsForm,
 	})
+	cli.Main()
 	runner.Run()
 }
@@ -0,0 +1,63 @@
+// TencentBlueKing is pleased to support the open source community by making
+// 蓝鲸智云-gopkg available.
+// Copyright (C) 2017-2022 THL A29 Limited, a Tencent company. All rights reserved.
+// Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at http://opensource.org/licenses/MIT
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Command check-migration checks a plugin project developed with
+// beego-runtime for the changes required by bk-plugin-runtime-go.
+//
+// Usage:
+//
+//	check-migration [-fix] [dir]
+//
+// It prints a diagnostic for each problem found and exits with status 1 if
+// there are errors not fixed. With -fix, it imports bk-plugin-runtime-go
+// runner instead of beego-runtime runner and rewrites hub.MustInstall calls
+// to hub.MustInstallV2 where possible. Dependence on the debug panel of
+// beego-runtime can not be detected and should be checked manually.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/TencentBlueKing/bk-plugin-framework-go/internal/migration"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "fix the fixable problems")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: check-migration [-fix] [dir]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	report, err := migration.Check(dir, migration.Options{Fix: *fix})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check-migration: %v\n", err)
+		os.Exit(1)
+	}
+	for _, d := range report.Diagnostics {
+		fmt.Println(d)
+	}
+	for _, file := range report.FixedFiles {
+		fmt.Printf("fixed %s\n", file)
+	}
+	if errors := report.Errors(); errors > 0 {
+		fmt.Printf("%d errors must be fixed before migrating to bk-plugin-runtime-go\n", errors)
+		os.Exit(1)
+	}
+}